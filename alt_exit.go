@@ -25,10 +25,17 @@ package logrus
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 var handlers = []func(){}
 
+// ExitHandlerTimeout bounds the time the exit handlers may take to run, after
+// which the program exits anyway, so that a hanging handler can't block the
+// shutdown forever. Logger.Exit bounds the flush of its outputs and hooks with
+// it too. It is not bounded when zero, which is the default.
+var ExitHandlerTimeout time.Duration
+
 func runHandler(handler func()) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -40,6 +47,27 @@ func runHandler(handler func()) {
 }
 
 func runHandlers() {
+	if ExitHandlerTimeout <= 0 {
+		runAllHandlers()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runAllHandlers()
+	}()
+
+	timer := time.NewTimer(ExitHandlerTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		fmt.Fprintln(os.Stderr, "Error: Logrus exit handlers timed out after", ExitHandlerTimeout)
+	}
+}
+
+func runAllHandlers() {
 	for _, handler := range handlers {
 		runHandler(handler)
 	}
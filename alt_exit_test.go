@@ -72,6 +72,61 @@ func TestDefer(t *testing.T) {
 	}
 }
 
+func TestFatalRunsHandlersBeforeExit(t *testing.T) {
+	defer func(saved []func()) { handlers = saved }(handlers)
+	handlers = nil
+
+	var results []string
+	DeferExitHandler(func() { results = append(results, "first") })
+	DeferExitHandler(func() { results = append(results, "second") })
+
+	logger := New()
+	logger.Out = ioutil.Discard
+	logger.ExitFunc = func(code int) {
+		results = append(results, "exit")
+		if code != 1 {
+			t.Fatalf("expected exit code 1, got %d", code)
+		}
+	}
+	logger.Fatal("fatal")
+
+	if strings.Join(results, ",") != "second,first,exit" {
+		t.Fatalf("expected the handlers to run last registered first before exiting, got %v", results)
+	}
+}
+
+func TestExitHandlerTimeout(t *testing.T) {
+	defer func(saved []func()) { handlers = saved }(handlers)
+	defer func(saved time.Duration) { ExitHandlerTimeout = saved }(ExitHandlerTimeout)
+	handlers = nil
+	ExitHandlerTimeout = 10 * time.Millisecond
+
+	hang := make(chan struct{})
+	defer close(hang)
+	ran := make(chan struct{})
+	DeferExitHandler(func() { <-hang })
+	DeferExitHandler(func() { close(ran) })
+
+	exited := false
+	logger := New()
+	logger.ExitFunc = func(int) { exited = true }
+
+	start := time.Now()
+	logger.Exit(1)
+
+	if !exited {
+		t.Fatal("expected the exit function to be called")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected a hanging handler to be abandoned after the timeout, exited after %s", elapsed)
+	}
+	select {
+	case <-ran:
+	default:
+		t.Fatal("expected the handlers before the hanging one to run")
+	}
+}
+
 func TestHandler(t *testing.T) {
 	testprog := testprogleader
 	testprog = append(testprog, getPackage()...)
@@ -0,0 +1,109 @@
+package logrus
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncWriter is an io.WriteCloser which writes to another writer from a
+// background goroutine, so that a stalled output, such as a network socket,
+// doesn't block the logging calls. Create it with NewAsyncWriter.
+//
+// Writes are queued and written in order. When the queue is full, writes are
+// dropped instead of blocking, and counted in Dropped. Errors of the wrapped
+// writer are reported to stderr.
+type AsyncWriter struct {
+	// Accessed atomically, kept first for 64-bit alignment on 32-bit platforms.
+	dropped uint64
+
+	w     io.Writer
+	queue chan asyncWrite
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type asyncWrite struct {
+	p       []byte
+	flushed chan struct{}
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to w with a queue of bufSize
+// writes, typically to be used as `Logger.Out`.
+func NewAsyncWriter(w io.Writer, bufSize int) *AsyncWriter {
+	aw := &AsyncWriter{
+		w:     w,
+		queue: make(chan asyncWrite, bufSize),
+		done:  make(chan struct{}),
+	}
+	go aw.run()
+	return aw
+}
+
+func (aw *AsyncWriter) run() {
+	defer close(aw.done)
+	for write := range aw.queue {
+		if write.flushed != nil {
+			close(write.flushed)
+			continue
+		}
+		if _, err := aw.w.Write(write.p); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+		}
+	}
+}
+
+// Write queues a copy of p without blocking. It only fails once the writer is
+// closed.
+func (aw *AsyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+	if aw.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	select {
+	case aw.queue <- asyncWrite{p: append([]byte(nil), p...)}:
+	default:
+		atomic.AddUint64(&aw.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Flush blocks until the writes queued so far are written.
+func (aw *AsyncWriter) Flush() error {
+	aw.mu.RLock()
+	if aw.closed {
+		aw.mu.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	aw.queue <- asyncWrite{flushed: flushed}
+	aw.mu.RUnlock()
+
+	<-flushed
+	return nil
+}
+
+// Close writes the queued writes and stops the background goroutine. It
+// doesn't close the wrapped writer.
+func (aw *AsyncWriter) Close() error {
+	aw.mu.Lock()
+	if !aw.closed {
+		aw.closed = true
+		close(aw.queue)
+	}
+	aw.mu.Unlock()
+
+	<-aw.done
+	return nil
+}
+
+// Dropped returns the number of writes dropped because the queue was full.
+func (aw *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&aw.dropped)
+}
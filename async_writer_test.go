@@ -0,0 +1,69 @@
+package logrus
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slowWriter struct {
+	mu    sync.Mutex
+	delay time.Duration
+	buf   bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterDoesNotBlock(t *testing.T) {
+	out := &slowWriter{delay: 20 * time.Millisecond}
+	aw := NewAsyncWriter(out, 4)
+
+	l := New()
+	l.SetOutput(aw)
+	l.SetFormatter(&TextFormatter{DisableTimestamp: true})
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		l.Info("line")
+	}
+	assert.WithinDuration(t, start, time.Now(), 500*time.Millisecond, "logging must not wait for the slow writer")
+	assert.NotZero(t, aw.Dropped())
+
+	require.NoError(t, aw.Close())
+	written := uint64(strings.Count(out.String(), "\n"))
+	assert.Equal(t, uint64(100), written+aw.Dropped(), "every write must be either written or dropped")
+
+	_, err := aw.Write([]byte("late\n"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+func TestAsyncWriterFlushKeepsOrder(t *testing.T) {
+	out := &slowWriter{delay: time.Millisecond}
+	aw := NewAsyncWriter(out, 16)
+
+	for _, line := range []string{"1\n", "2\n", "3\n"} {
+		_, err := aw.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, aw.Flush())
+	assert.Equal(t, "1\n2\n3\n", out.String())
+	assert.Zero(t, aw.Dropped())
+	require.NoError(t, aw.Close())
+}
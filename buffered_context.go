@@ -0,0 +1,89 @@
+package logrus
+
+import (
+	"context"
+	"sync"
+)
+
+type bufferedContextKey struct{}
+
+// BufferedContext returns a context which holds back the output of the entries
+// logged with it (see WithContext), and a function to release them once the
+// outcome is known: `release(true)` writes them to the output of their logger
+// and level, in order, while `release(false)` drops them. This allows verbose
+// logging for failed requests only:
+//
+//	ctx, release := logrus.BufferedContext(r.Context())
+//	err := handle(ctx)
+//	release(err != nil)
+//
+// Entries logged after the release are written directly. Fatal and panic
+// entries are never held back. Hooks fire when the entry is logged, but the
+// PostFormat hooks only fire when a held back entry is written, and never for
+// the dropped entries.
+func BufferedContext(ctx context.Context) (context.Context, func(flush bool)) {
+	buf := &contextBuffer{}
+	return context.WithValue(ctx, bufferedContextKey{}, buf), buf.release
+}
+
+type contextBuffer struct {
+	mu       sync.Mutex
+	released bool
+	records  []bufferedRecord
+}
+
+type bufferedRecord struct {
+	entry      *Entry
+	serialized []byte
+}
+
+// contextBuffer returns the buffer the entry must be held back in, if any.
+func (entry *Entry) contextBuffer() *contextBuffer {
+	if entry.Context == nil || entry.Level <= FatalLevel {
+		return nil
+	}
+	buf, _ := entry.Context.Value(bufferedContextKey{}).(*contextBuffer)
+	return buf
+}
+
+// add holds back the entry with a copy of its serialized form, and reports
+// false if the buffer was already released.
+func (buf *contextBuffer) add(entry *Entry, serialized []byte) bool {
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	if buf.released {
+		return false
+	}
+	buf.records = append(buf.records, bufferedRecord{
+		entry:      entry,
+		serialized: append([]byte(nil), serialized...),
+	})
+	return true
+}
+
+func (buf *contextBuffer) release(flush bool) {
+	buf.mu.Lock()
+	records := buf.records
+	buf.records = nil
+	buf.released = true
+	buf.mu.Unlock()
+
+	if !flush {
+		return
+	}
+	for _, record := range records {
+		entry, logger := record.entry, record.entry.Logger
+		logger.mu.Lock()
+		_, err := logger.output(entry.Level).Write(record.serialized)
+		flushOnLevel := logger.FlushOnLevel
+		logger.mu.Unlock()
+		if err != nil {
+			logger.writeFailed(err)
+			continue
+		}
+		entry.firePostFormatHooks(record.serialized)
+		if entry.Level <= flushOnLevel {
+			entry.flushHooks()
+		}
+	}
+}
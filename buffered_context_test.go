@@ -0,0 +1,84 @@
+package logrus
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetLevel(DebugLevel)
+	l.SetFormatter(&TextFormatter{DisableColors: true, DisableTimestamp: true})
+
+	ctx, release := BufferedContext(context.Background())
+	l.WithContext(ctx).Debug("step 1")
+	l.WithContext(ctx).WithField("k", "v").Error("step 2")
+	l.Info("unrelated")
+	assert.Equal(t, "level=info msg=unrelated\n", buf.String())
+
+	release(true)
+	assert.Equal(t, "level=info msg=unrelated\nlevel=debug msg=\"step 1\"\nlevel=error msg=\"step 2\" k=v\n", buf.String())
+
+	buf.Reset()
+	l.WithContext(ctx).Info("after release")
+	assert.Equal(t, "level=info msg=\"after release\"\n", buf.String())
+}
+
+func TestBufferedContextDiscard(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+
+	ctx, release := BufferedContext(context.Background())
+	l.WithContext(ctx).Info("dropped")
+	release(false)
+	release(true)
+	assert.Empty(t, buf.String())
+}
+
+type postFormatRecorder struct {
+	written []string
+	flushed int
+}
+
+func (h *postFormatRecorder) Levels() []Level   { return AllLevels }
+func (h *postFormatRecorder) Fire(*Entry) error { return nil }
+func (h *postFormatRecorder) Flush() error {
+	h.flushed++
+	return nil
+}
+
+func (h *postFormatRecorder) PostFormat(entry *Entry, formatted []byte) error {
+	h.written = append(h.written, string(formatted))
+	return nil
+}
+
+func TestBufferedContextPostFormatHooks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	hook := &postFormatRecorder{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&TextFormatter{DisableColors: true, DisableTimestamp: true})
+	l.FlushOnLevel = ErrorLevel
+	l.AddHook(hook)
+
+	ctx, release := BufferedContext(context.Background())
+	l.WithContext(ctx).Error("dropped")
+	release(false)
+	assert.Empty(t, buf.String())
+	assert.Empty(t, hook.written, "a dropped entry must never reach the PostFormat hooks")
+	assert.Equal(t, 0, hook.flushed, "the hooks must not be flushed for a dropped entry")
+
+	ctx, release = BufferedContext(context.Background())
+	l.WithContext(ctx).Error("held")
+	assert.Empty(t, hook.written, "a held back entry is not written yet")
+	release(true)
+	assert.Equal(t, []string{"level=error msg=held\n"}, hook.written)
+	assert.Equal(t, buf.String(), hook.written[0])
+	assert.Equal(t, 1, hook.flushed)
+}
@@ -0,0 +1,28 @@
+package logrus
+
+import "reflect"
+
+// Diff returns the fields which differ between two snapshots, for state change
+// logging:
+//
+//	log.WithFields(logrus.Diff(before, after)).Info("state changed")
+//
+// Each changed, added or removed key maps to a `Fields{"before": ..., "after": ...}`
+// pair. A key missing from a snapshot has a nil value on that side, rendered as
+// `null` by the JSONFormatter and `<nil>` by the TextFormatter. Values are
+// compared with reflect.DeepEqual.
+func Diff(old, new Fields) Fields {
+	diff := make(Fields)
+	for k, before := range old {
+		after, ok := new[k]
+		if !ok || !reflect.DeepEqual(before, after) {
+			diff[k] = Fields{"before": before, "after": after}
+		}
+	}
+	for k, after := range new {
+		if _, ok := old[k]; !ok {
+			diff[k] = Fields{"before": nil, "after": after}
+		}
+	}
+	return diff
+}
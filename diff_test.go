@@ -0,0 +1,36 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	old := Fields{"same": 1, "changed": "a", "removed": true, "slice": []int{1}}
+	new := Fields{"same": 1, "changed": "b", "added": 2.5, "slice": []int{1}}
+
+	assert.Equal(t, Fields{
+		"changed": Fields{"before": "a", "after": "b"},
+		"removed": Fields{"before": true, "after": nil},
+		"added":   Fields{"before": nil, "after": 2.5},
+	}, Diff(old, new))
+
+	assert.Empty(t, Diff(old, old))
+}
+
+func TestDiffJSONRendering(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+
+	l.WithFields(Diff(Fields{"k": 1}, Fields{})).Info("state changed")
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, map[string]interface{}{"before": float64(1), "after": nil}, data["k"])
+}
@@ -0,0 +1,80 @@
+package logrus
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the text encoding written by the writer of NewEncodingWriter.
+type Encoding int
+
+const (
+	// EncodingUTF8BOM is UTF-8 prefixed with a byte order mark.
+	EncodingUTF8BOM Encoding = iota
+	// EncodingUTF16LE is little-endian UTF-16 prefixed with a byte order mark.
+	EncodingUTF16LE
+)
+
+// NewEncodingWriter wraps w, typically to be used as `Logger.Out`, so that the
+// output is written in the given encoding for consumers which require it,
+// such as some Windows log viewers. The byte order mark is written once, with
+// the first write.
+//
+// The formatters produce UTF-8, and each write is expected to hold complete
+// UTF-8 sequences. When transcoding, invalid sequences are replaced with
+// U+FFFD.
+func NewEncodingWriter(w io.Writer, enc Encoding) io.Writer {
+	return &encodingWriter{w: w, enc: enc}
+}
+
+type encodingWriter struct {
+	w   io.Writer
+	enc Encoding
+
+	mu         sync.Mutex
+	bomWritten bool
+	buf        []byte
+}
+
+func (ew *encodingWriter) Write(p []byte) (int, error) {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	ew.buf = ew.buf[:0]
+	switch ew.enc {
+	case EncodingUTF8BOM:
+		if !ew.bomWritten {
+			ew.buf = append(ew.buf, 0xEF, 0xBB, 0xBF)
+		}
+		ew.buf = append(ew.buf, p...)
+	case EncodingUTF16LE:
+		if !ew.bomWritten {
+			ew.buf = append(ew.buf, 0xFF, 0xFE)
+		}
+		for i := 0; i < len(p); {
+			r, size := utf8.DecodeRune(p[i:])
+			i += size
+			if r >= 0x10000 {
+				r1, r2 := utf16.EncodeRune(r)
+				ew.buf = appendUTF16LE(ew.buf, r1)
+				r = r2
+			}
+			ew.buf = appendUTF16LE(ew.buf, r)
+		}
+	default:
+		return 0, fmt.Errorf("unknown encoding %d", ew.enc)
+	}
+
+	if _, err := ew.w.Write(ew.buf); err != nil {
+		return 0, err
+	}
+	ew.bomWritten = true
+	return len(p), nil
+}
+
+func appendUTF16LE(b []byte, r rune) []byte {
+	return append(b, byte(r), byte(r>>8))
+}
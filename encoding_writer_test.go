@@ -0,0 +1,52 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodingWriterUTF8BOM(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewEncodingWriter(buf, EncodingUTF8BOM)
+
+	n, err := w.Write([]byte("héllo\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 7, n)
+	_, err = w.Write([]byte("world\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "\xEF\xBB\xBFhéllo\nworld\n", buf.String())
+}
+
+func TestEncodingWriterUTF16LE(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewEncodingWriter(buf, EncodingUTF16LE)
+
+	n, err := w.Write([]byte("é𝄞\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 7, n)
+	_, err = w.Write([]byte("a"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0xFF, 0xFE, // byte order mark
+		0xE9, 0x00, // é
+		0x34, 0xD8, 0x1E, 0xDD, // 𝄞, as a surrogate pair
+		0x0A, 0x00, // \n
+		0x61, 0x00, // a
+	}, buf.Bytes())
+}
+
+func TestEncodingWriterAsLoggerOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(NewEncodingWriter(buf, EncodingUTF8BOM))
+	l.SetFormatter(&TextFormatter{DisableTimestamp: true})
+
+	l.Info("first")
+	l.Info("second")
+	assert.Equal(t, "\xEF\xBB\xBFlevel=info msg=first\nlevel=info msg=second\n", buf.String())
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -68,6 +69,13 @@ type Entry struct {
 
 	// err may contain a field formatting error
 	err string
+
+	// order contains the keys of Data in the order they were added
+	order []string
+
+	// callerHidden is set when the caller was only looked up for the package
+	// levels, ReportCaller being off when the entry was logged
+	callerHidden bool
 }
 
 func NewEntry(logger *Logger) *Entry {
@@ -83,12 +91,12 @@ func (entry *Entry) Dup() *Entry {
 	for k, v := range entry.Data {
 		data[k] = v
 	}
-	return &Entry{Logger: entry.Logger, Data: data, Time: entry.Time, Context: entry.Context, err: entry.err}
+	return &Entry{Logger: entry.Logger, Data: data, Time: entry.Time, Context: entry.Context, err: entry.err, order: entry.order}
 }
 
 // Returns the bytes representation of this entry from the formatter.
 func (entry *Entry) Bytes() ([]byte, error) {
-	return entry.Logger.Formatter.Format(entry)
+	return entry.Logger.GetFormatter().Format(entry)
 }
 
 // Returns the string representation from the reader and ultimately the
@@ -107,47 +115,182 @@ func (entry *Entry) WithError(err error) *Entry {
 	return entry.WithField(ErrorKey, err)
 }
 
+// Add several errors as a single ErrorList field to the Entry, using the key
+// defined in ErrorKey, e.g. the errors of a fan-out.
+func (entry *Entry) WithErrors(errs ...error) *Entry {
+	return entry.WithField(ErrorKey, ErrorList(errs))
+}
+
 // Add a context to the Entry.
 func (entry *Entry) WithContext(ctx context.Context) *Entry {
 	dataCopy := make(Fields, len(entry.Data))
 	for k, v := range entry.Data {
 		dataCopy[k] = v
 	}
-	return &Entry{Logger: entry.Logger, Data: dataCopy, Time: entry.Time, err: entry.err, Context: ctx}
+	return &Entry{Logger: entry.Logger, Data: dataCopy, Time: entry.Time, err: entry.err, Context: ctx, order: entry.order}
 }
 
 // Add a single field to the Entry.
+//
+// Like WithFields, it copies the fields of the Entry so that the new Entry can
+// be modified without affecting this one, but it doesn't allocate a Fields map
+// for the single field.
 func (entry *Entry) WithField(key string, value interface{}) *Entry {
-	return entry.WithFields(Fields{key: value})
+	data := make(Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	fieldErr, order := entry.err, entry.order
+	if tmp := entry.fieldError(key, value); tmp != "" {
+		fieldErr = joinFieldErrors(fieldErr, tmp)
+	} else {
+		if _, ok := data[key]; !ok {
+			// Never append in place, the parent entry shares the backing array.
+			order = append(order[:len(order):len(order)], key)
+		}
+		entry.mergeField(data, key, value)
+	}
+	return &Entry{Logger: entry.Logger, Data: data, Time: entry.Time, err: fieldErr, Context: entry.Context, order: order}
 }
 
-// Add a map of fields to the Entry.
+// Add a map of fields to the Entry. The fields already in the Entry are
+// overwritten by the new values of their keys, or merged according to the
+// DuplicateKeyPolicy of the logger. See WithDefaults to keep them instead.
 func (entry *Entry) WithFields(fields Fields) *Entry {
 	data := make(Fields, len(entry.Data)+len(fields))
 	for k, v := range entry.Data {
 		data[k] = v
 	}
 	fieldErr := entry.err
+	var added []string
 	for k, v := range fields {
-		isErrField := false
-		if t := reflect.TypeOf(v); t != nil {
-			switch {
-			case t.Kind() == reflect.Func, t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Func:
-				isErrField = true
+		if tmp := entry.fieldError(k, v); tmp != "" {
+			fieldErr = joinFieldErrors(fieldErr, tmp)
+		} else {
+			if _, ok := data[k]; !ok {
+				added = append(added, k)
 			}
+			entry.mergeField(data, k, v)
 		}
-		if isErrField {
-			tmp := fmt.Sprintf("can not add field %q", k)
-			if fieldErr != "" {
-				fieldErr = entry.err + ", " + tmp
-			} else {
-				fieldErr = tmp
-			}
-		} else {
-			data[k] = v
+	}
+	order := entry.order
+	if len(added) > 0 {
+		// The fields of a single call have no order, keep the output stable.
+		sort.Strings(added)
+		// Never append in place, the parent entry shares the backing array.
+		order = append(order[:len(order):len(order)], added...)
+	}
+	return &Entry{Logger: entry.Logger, Data: data, Time: entry.Time, err: fieldErr, Context: entry.Context, order: order}
+}
+
+// resolveLazyValues replaces the LazyValue fields by their value.
+func (entry *Entry) resolveLazyValues() {
+	for k, v := range entry.Data {
+		if lazy, ok := v.(LazyValue); ok {
+			entry.Data[k] = lazy()
+		}
+	}
+}
+
+// fieldKeys returns the keys of data in the order they were added to the
+// entry, followed by the keys the entry doesn't know the order of, sorted.
+func (entry *Entry) fieldKeys(data Fields) []string {
+	keys := make([]string, 0, len(data))
+	seen := make(map[string]struct{}, len(entry.order))
+	for _, k := range entry.order {
+		if _, ok := data[k]; ok {
+			keys = append(keys, k)
+			seen[k] = struct{}{}
+		}
+	}
+	n := len(keys)
+	for k := range data {
+		if _, ok := seen[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[n:])
+	return keys
+}
+
+// WithDefaults adds the fields whose keys are not in the Entry yet, and keeps
+// the values of those already set.
+func (entry *Entry) WithDefaults(fields Fields) *Entry {
+	defaults := make(Fields, len(fields))
+	for k, v := range fields {
+		if _, ok := entry.Data[k]; !ok {
+			defaults[k] = v
+		}
+	}
+	return entry.WithFields(defaults)
+}
+
+func joinFieldErrors(fieldErr, tmp string) string {
+	if fieldErr != "" {
+		return fieldErr + ", " + tmp
+	}
+	return tmp
+}
+
+// mergeField sets a field in data, following the DuplicateKeyPolicy of the
+// logger when the key is already present.
+func (entry *Entry) mergeField(data Fields, key string, value interface{}) {
+	prev, ok := data[key]
+	if !ok || entry.Logger == nil {
+		data[key] = value
+		return
+	}
+
+	switch entry.Logger.DuplicateKeyPolicy {
+	case DuplicateKeyKeepFirst:
+	case DuplicateKeyCollect:
+		collected, ok := prev.(CollectedValues)
+		if !ok {
+			collected = CollectedValues{prev}
+		}
+		// Never append in place, the parent entry shares the backing array.
+		data[key] = append(collected[:len(collected):len(collected)], value)
+	default:
+		data[key] = value
+	}
+}
+
+// fieldError returns why a field can not be added to the Entry, or an empty
+// string if it can.
+func (entry *Entry) fieldError(key string, value interface{}) string {
+	if _, ok := value.(LazyValue); ok {
+		return ""
+	}
+	if t := reflect.TypeOf(value); t != nil {
+		switch {
+		case t.Kind() == reflect.Func, t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Func:
+			return fmt.Sprintf("can not add field %q", key)
+		}
+	}
+	return ""
+}
+
+// checkFieldSchema drops the fields whose kind doesn't match the schema,
+// records them in the `logrus_error` field and returns the violations.
+func (entry *Entry) checkFieldSchema(schema map[string]reflect.Kind) []error {
+	var errs []error
+	for key, kind := range schema {
+		value, ok := entry.Data[key]
+		if !ok {
+			continue
+		}
+		if got := reflect.ValueOf(value).Kind(); got != kind {
+			delete(entry.Data, key)
+			errs = append(errs, &FieldSchemaError{Key: key, Want: kind, Got: got})
 		}
 	}
-	return &Entry{Logger: entry.Logger, Data: data, Time: entry.Time, err: fieldErr, Context: entry.Context}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].(*FieldSchemaError).Key < errs[j].(*FieldSchemaError).Key
+	})
+	for _, err := range errs {
+		entry.err = joinFieldErrors(entry.err, err.Error())
+	}
+	return errs
 }
 
 // Overrides the time of the Entry.
@@ -156,7 +299,7 @@ func (entry *Entry) WithTime(t time.Time) *Entry {
 	for k, v := range entry.Data {
 		dataCopy[k] = v
 	}
-	return &Entry{Logger: entry.Logger, Data: dataCopy, Time: t, err: entry.err, Context: entry.Context}
+	return &Entry{Logger: entry.Logger, Data: dataCopy, Time: t, err: entry.err, Context: entry.Context, order: entry.order}
 }
 
 // getPackageName reduces a fully qualified function name to the package name
@@ -175,8 +318,9 @@ func getPackageName(f string) string {
 	return f
 }
 
-// getCaller retrieves the name of the first non-logrus calling function
-func getCaller() *runtime.Frame {
+// getCaller retrieves the name of the first calling function outside of logrus
+// and of the skipped packages, then goes up skipFrames more frames
+func getCaller(skipFrames int, skipPackages []string) *runtime.Frame {
 	// cache this package's fully-qualified name
 	callerInitOnce.Do(func() {
 		pcs := make([]uintptr, maximumCallerDepth)
@@ -203,7 +347,11 @@ func getCaller() *runtime.Frame {
 		pkg := getPackageName(f.Function)
 
 		// If the caller isn't part of this package, we're done
-		if pkg != logrusPackage {
+		if pkg != logrusPackage && !hasAnyPrefix(pkg, skipPackages) {
+			if skipFrames > 0 {
+				skipFrames--
+				continue
+			}
 			return &f //nolint:scopelint
 		}
 	}
@@ -212,46 +360,86 @@ func getCaller() *runtime.Frame {
 	return nil
 }
 
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CallerPackage returns the import path of the package of the caller, or ""
+// when the caller is not known, i.e. when neither ReportCaller nor a package
+// level is set.
+func (entry *Entry) CallerPackage() string {
+	if entry.Caller == nil {
+		return ""
+	}
+	return getPackageName(entry.Caller.Function)
+}
+
+// HasCaller reports whether the caller of the entry is to be written, i.e.
+// whether ReportCaller was set when the entry was logged.
 func (entry Entry) HasCaller() (has bool) {
-	return entry.Logger != nil &&
-		entry.Logger.ReportCaller &&
-		entry.Caller != nil
+	return entry.Caller != nil && !entry.callerHidden
 }
 
 func (entry *Entry) log(level Level, msg string) {
 	var buffer *bytes.Buffer
 
-	newEntry := entry.Dup()
-
-	if newEntry.Time.IsZero() {
-		newEntry.Time = time.Now()
+	if entry.Logger.discard {
+		if level <= PanicLevel {
+			panic(entry.prepare(level, msg))
+		}
+		return
 	}
 
-	newEntry.Level = level
-	newEntry.Message = msg
+	newEntry := entry.prepare(level, msg)
 
 	newEntry.Logger.mu.Lock()
-	reportCaller := newEntry.Logger.ReportCaller
+	enabled := newEntry.Logger.packageLevelEnabled(level, newEntry.CallerPackage())
+	flushOnLevel := newEntry.Logger.FlushOnLevel
+	onEntry := newEntry.Logger.OnEntry
 	bufPool := newEntry.getBufferPool()
+	schema, onFieldError := newEntry.Logger.FieldSchema, newEntry.Logger.OnFieldError
 	newEntry.Logger.mu.Unlock()
 
-	if reportCaller {
-		newEntry.Caller = getCaller()
+	if !enabled {
+		return
 	}
 
-	newEntry.fireHooks()
-	buffer = bufPool.Get()
-	defer func() {
-		newEntry.Buffer = nil
+	if len(schema) > 0 {
+		for _, err := range newEntry.checkFieldSchema(schema) {
+			if onFieldError != nil {
+				onFieldError(err)
+			}
+		}
+	}
+
+	if onEntry != nil {
+		onEntry(newEntry)
+	}
+
+	if newEntry.fireHooks() {
+		buffer = bufPool.Get()
+		defer func() {
+			newEntry.Buffer = nil
+			buffer.Reset()
+			bufPool.Put(buffer)
+		}()
 		buffer.Reset()
-		bufPool.Put(buffer)
-	}()
-	buffer.Reset()
-	newEntry.Buffer = buffer
+		newEntry.Buffer = buffer
 
-	newEntry.write()
+		if serialized := newEntry.write(); serialized != nil {
+			newEntry.firePostFormatHooks(serialized)
+			if level <= flushOnLevel {
+				newEntry.flushHooks()
+			}
+		}
 
-	newEntry.Buffer = nil
+		newEntry.Buffer = nil
+	}
 
 	// To avoid Entry#log() returning a value that only would make sense for
 	// panic() to use in Entry#Panic(), we avoid the allocation by checking
@@ -268,7 +456,9 @@ func (entry *Entry) getBufferPool() (pool BufferPool) {
 	return bufferPool
 }
 
-func (entry *Entry) fireHooks() {
+// fireHooks fires the hooks of the entry level and reports whether the entry
+// must be written, i.e. whether no FilterHook dropped it.
+func (entry *Entry) fireHooks() bool {
 	var tmpHooks LevelHooks
 	entry.Logger.mu.Lock()
 	tmpHooks = make(LevelHooks, len(entry.Logger.Hooks))
@@ -277,31 +467,160 @@ func (entry *Entry) fireHooks() {
 	}
 	entry.Logger.mu.Unlock()
 
-	err := tmpHooks.Fire(entry.Level, entry)
+	keep, err := tmpHooks.fire(entry.Level, entry)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
 	}
+	return keep
 }
 
-func (entry *Entry) write() {
+func (entry *Entry) firePostFormatHooks(serialized []byte) {
+	entry.Logger.mu.Lock()
+	tmpHooks := LevelHooks{entry.Level: entry.Logger.Hooks[entry.Level]}
+	entry.Logger.mu.Unlock()
+
+	err := tmpHooks.FirePostFormat(entry.Level, entry, serialized)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to fire post format hook: %v\n", err)
+	}
+}
+
+func (entry *Entry) flushHooks() {
+	entry.Logger.mu.Lock()
+	hooks := entry.Logger.Hooks[entry.Level]
+	entry.Logger.mu.Unlock()
+
+	for _, hook := range hooks {
+		if f, ok := hook.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to flush hook: %v\n", err)
+			}
+		}
+	}
+}
+
+// write formats the entry to the logger output and returns the bytes that
+// were written, or nil if formatting or writing failed or if the entry was
+// held back by a BufferedContext.
+func (entry *Entry) write() []byte {
+	serialized, err := entry.writeLocked()
+	if err != nil {
+		entry.Logger.writeFailed(err)
+		return nil
+	}
+	return serialized
+}
+
+// writeLocked formats and writes the entry under the logger lock. Formatting
+// errors are reported to stderr, while output errors are returned.
+func (entry *Entry) writeLocked() ([]byte, error) {
 	entry.Logger.mu.Lock()
 	defer entry.Logger.mu.Unlock()
 	serialized, err := entry.Logger.Formatter.Format(entry)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to obtain reader, %v\n", err)
-		return
+		return nil, nil
+	}
+	if buf := entry.contextBuffer(); buf != nil && buf.add(entry, serialized) {
+		// Not written yet, see contextBuffer.release.
+		return nil, nil
+	}
+	out := entry.Logger.output(entry.Level)
+	if _, err := out.Write(serialized); err != nil {
+		return nil, err
+	}
+	if f, ok := out.(flusher); ok && entry.Level <= entry.Logger.FlushOnLevel {
+		if err := f.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to flush log, %v\n", err)
+		}
+	}
+	return serialized, nil
+}
+
+// IsLevelEnabled checks if the log level of the entry logger is greater than
+// the level param
+func (entry *Entry) IsLevelEnabled(level Level) bool {
+	return entry.Logger.IsLevelEnabled(level)
+}
+
+// prepare returns a copy of the entry as it is logged at the given level: with
+// its time, level and message set, and the caller and fields added by the
+// logger.
+func (entry *Entry) prepare(level Level, msg string) *Entry {
+	newEntry := entry.Dup()
+
+	if newEntry.Time.IsZero() {
+		newEntry.Time = time.Now()
 	}
-	if _, err := entry.Logger.Out.Write(serialized); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+
+	newEntry.Level = level
+	newEntry.Message = msg
+
+	newEntry.Logger.mu.Lock()
+	reportCaller := newEntry.Logger.ReportCaller
+	callerSkipFrames, callerSkipPackages := newEntry.Logger.CallerSkipFrames, newEntry.Logger.CallerSkipPackages
+	instanceID, instanceIDKey := newEntry.Logger.InstanceID, newEntry.Logger.InstanceIDKey
+	hasPackageLevels := len(newEntry.Logger.packageLevels) > 0
+	for k, v := range newEntry.Logger.DefaultFields {
+		if _, ok := newEntry.Data[k]; !ok {
+			newEntry.Data[k] = v
+		}
 	}
+	newEntry.Logger.mu.Unlock()
+
+	newEntry.resolveLazyValues()
+
+	if instanceID != "" {
+		if instanceIDKey == "" {
+			instanceIDKey = FieldKeyInstanceID
+		}
+		if _, ok := newEntry.Data[instanceIDKey]; !ok {
+			newEntry.Data[instanceIDKey] = instanceID
+		}
+	}
+
+	if reportCaller || hasPackageLevels {
+		newEntry.Caller = getCaller(callerSkipFrames, callerSkipPackages)
+		newEntry.callerHidden = !reportCaller
+	}
+
+	return newEntry
+}
+
+// Render returns the entry formatted as it would be written if logged at the
+// given level, without writing it, e.g. to ship it through another transport.
+// The time, level, message, caller and logger fields are set as for a log
+// call, but the level is not checked, OnEntry is not called and the hooks are
+// not fired.
+func (entry *Entry) Render(level Level, args ...interface{}) ([]byte, error) {
+	return entry.prepare(level, fmt.Sprint(args...)).Bytes()
 }
 
 // Log will log a message at the level given as parameter.
-// Warning: using Log at Panic or Fatal level will not respectively Panic nor Exit.
-// For this behaviour Entry.Panic or Entry.Fatal should be used instead.
+// Like Entry.Panic and Entry.Fatal, using Log at Panic or Fatal level
+// respectively panics or exits, even when the Fatal level is disabled.
 func (entry *Entry) Log(level Level, args ...interface{}) {
 	if entry.Logger.IsLevelEnabled(level) {
 		entry.log(level, fmt.Sprint(args...))
+	} else if entry.Logger.observesDisabled() {
+		entry.observe(level, fmt.Sprint(args...))
+	}
+	if level == FatalLevel {
+		entry.Logger.Exit(1)
+	}
+}
+
+// observe passes an entry of a disabled level to the OnEntry callback of the
+// logger, without logging it. The entry is built as the logged ones are.
+func (entry *Entry) observe(level Level, msg string) {
+	newEntry := entry.prepare(level, msg)
+
+	newEntry.Logger.mu.Lock()
+	onEntry := newEntry.Logger.OnEntry
+	newEntry.Logger.mu.Unlock()
+
+	if onEntry != nil {
+		onEntry(newEntry)
 	}
 }
 
@@ -335,7 +654,6 @@ func (entry *Entry) Error(args ...interface{}) {
 
 func (entry *Entry) Fatal(args ...interface{}) {
 	entry.Log(FatalLevel, args...)
-	entry.Logger.Exit(1)
 }
 
 func (entry *Entry) Panic(args ...interface{}) {
@@ -345,7 +663,7 @@ func (entry *Entry) Panic(args ...interface{}) {
 // Entry Printf family functions
 
 func (entry *Entry) Logf(level Level, format string, args ...interface{}) {
-	if entry.Logger.IsLevelEnabled(level) {
+	if level == FatalLevel || entry.Logger.IsLevelEnabled(level) || entry.Logger.observesDisabled() {
 		entry.Log(level, fmt.Sprintf(format, args...))
 	}
 }
@@ -380,7 +698,6 @@ func (entry *Entry) Errorf(format string, args ...interface{}) {
 
 func (entry *Entry) Fatalf(format string, args ...interface{}) {
 	entry.Logf(FatalLevel, format, args...)
-	entry.Logger.Exit(1)
 }
 
 func (entry *Entry) Panicf(format string, args ...interface{}) {
@@ -390,7 +707,7 @@ func (entry *Entry) Panicf(format string, args ...interface{}) {
 // Entry Println family functions
 
 func (entry *Entry) Logln(level Level, args ...interface{}) {
-	if entry.Logger.IsLevelEnabled(level) {
+	if level == FatalLevel || entry.Logger.IsLevelEnabled(level) || entry.Logger.observesDisabled() {
 		entry.Log(level, entry.sprintlnn(args...))
 	}
 }
@@ -425,7 +742,6 @@ func (entry *Entry) Errorln(args ...interface{}) {
 
 func (entry *Entry) Fatalln(args ...interface{}) {
 	entry.Logln(FatalLevel, args...)
-	entry.Logger.Exit(1)
 }
 
 func (entry *Entry) Panicln(args ...interface{}) {
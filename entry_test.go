@@ -3,7 +3,10 @@ package logrus
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,6 +91,37 @@ func TestEntryWithContextCopiesData(t *testing.T) {
 	assert.Empty(val)
 }
 
+type contextRecorderHook struct {
+	contexts []context.Context
+}
+
+func (h *contextRecorderHook) Levels() []Level {
+	return AllLevels
+}
+
+func (h *contextRecorderHook) Fire(e *Entry) error {
+	h.contexts = append(h.contexts, e.Context)
+	return nil
+}
+
+func TestLoggerContextHelpers(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.WithValue(context.Background(), "request_id", "42")
+
+	hook := &contextRecorderHook{}
+	logger := New()
+	logger.Out = &bytes.Buffer{}
+	logger.AddHook(hook)
+
+	logger.InfoContext(ctx, "info")
+	logger.ErrorContext(ctx, "error")
+	logger.DebugContext(ctx, "filtered")
+	logger.WithContext(ctx).WithField("k", "v").WithFields(Fields{"k2": "v2"}).Warn("chained")
+	logger.Info("no context")
+
+	assert.Equal([]context.Context{ctx, ctx, ctx, nil}, hook.contexts)
+}
+
 func TestEntryWithTimeCopiesData(t *testing.T) {
 	assert := assert.New(t)
 
@@ -123,6 +157,63 @@ func TestEntryWithTimeCopiesData(t *testing.T) {
 	assert.Empty(val)
 }
 
+func TestEntryDupCopiesData(t *testing.T) {
+	assert := assert.New(t)
+
+	fixed := time.Date(2019, 3, 14, 15, 9, 26, 0, time.UTC)
+	ctx := context.WithValue(context.Background(), "foo", "bar")
+	parentEntry := NewEntry(New()).WithField("parentKey", "parentValue").WithTime(fixed).WithContext(ctx)
+
+	dup := parentEntry.Dup()
+	assert.Equal(parentEntry.Data, dup.Data)
+	assert.Equal(fixed, dup.Time)
+	assert.Equal(ctx, dup.Context)
+	assert.Same(parentEntry.Logger, dup.Logger)
+
+	// Modify data stored in the duplicate entry
+	dup.Data["childKey"] = "childValue"
+	dup.Data["parentKey"] = "changed"
+
+	// Verify that the data change has not affected the original entry
+	assert.NotContains(parentEntry.Data, "childKey")
+	assert.Equal("parentValue", parentEntry.Data["parentKey"])
+}
+
+func TestEntryWithTimeIsHonored(t *testing.T) {
+	assert := assert.New(t)
+
+	fixed := time.Date(2019, 3, 14, 15, 9, 26, 0, time.UTC)
+	buffer := &bytes.Buffer{}
+	logger := New()
+	logger.Out = buffer
+	logger.Formatter = &TextFormatter{DisableColors: true, TimestampFormat: time.RFC3339}
+
+	NewEntry(logger).WithTime(fixed).Info("replayed")
+	assert.Equal("time=\"2019-03-14T15:09:26Z\" level=info msg=replayed\n", buffer.String())
+
+	// Without WithTime, entries are stamped when logged
+	buffer.Reset()
+	before := time.Now().Truncate(time.Second)
+	NewEntry(logger).Info("live")
+	fields := strings.SplitN(buffer.String(), " ", 2)
+	stamped, err := time.Parse("time=\"2006-01-02T15:04:05Z07:00\"", fields[0])
+	assert.NoError(err)
+	assert.False(stamped.Before(before), "entry stamped at %s, before %s", stamped, before)
+}
+
+func TestEntryWithDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	entry := NewEntry(New()).WithField("service", "worker")
+	withDefaults := entry.WithDefaults(Fields{"service": "api", "region": "eu"})
+
+	assert.Equal("worker", withDefaults.Data["service"])
+	assert.Equal("eu", withDefaults.Data["region"])
+	assert.NotContains(entry.Data, "region", "the parent entry must not be modified")
+
+	assert.Equal("api", New().WithDefaults(Fields{"service": "api"}).Data["service"])
+}
+
 func TestEntryPanicln(t *testing.T) {
 	errBoom := fmt.Errorf("boom time")
 
@@ -299,3 +390,95 @@ func TestEntryFormatterRace(t *testing.T) {
 		entry.Info("should not race")
 	}()
 }
+
+type recordingHook struct {
+	entries []Entry
+}
+
+func (h *recordingHook) Levels() []Level {
+	return AllLevels
+}
+
+func (h *recordingHook) Fire(e *Entry) error {
+	e.Data["fired_at_"+e.Level.String()] = true
+	h.entries = append(h.entries, *e.Dup())
+	return nil
+}
+
+func TestReusedEntryLogsIndependently(t *testing.T) {
+	var buffer bytes.Buffer
+	hook := &recordingHook{}
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = &TextFormatter{DisableColors: true, DisableTimestamp: true}
+	logger.ReportCaller = true
+	logger.AddHook(hook)
+
+	entry := logger.WithField("request", "abc")
+	entry.Info("first")
+	time.Sleep(time.Millisecond)
+	entry.Error("second")
+
+	assert.Equal(t, Fields{"request": "abc"}, entry.Data, "hooks must not modify the reused entry")
+	assert.True(t, entry.Time.IsZero(), "the log time must not be stored on the reused entry")
+	assert.Nil(t, entry.Buffer)
+	assert.Nil(t, entry.Caller)
+
+	if assert.Len(t, hook.entries, 2) {
+		first, second := hook.entries[0], hook.entries[1]
+		assert.NotContains(t, second.Data, "fired_at_info")
+		assert.True(t, second.Time.After(first.Time), "each call must have its own timestamp")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], "fired_at_info=true")
+		assert.NotContains(t, lines[1], "fired_at_info")
+		assert.Contains(t, lines[1], "fired_at_error=true")
+	}
+}
+
+func TestCallerIndependentOfWithFieldsChainDepth(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = &JSONFormatter{}
+	logger.ReportCaller = true
+
+	// The caller lookup skips every logrus frame, so from within the package
+	// the reported caller is the test runner.
+	logger.Print("direct")
+	logger.WithFields(Fields{"a": 1}).
+		WithFields(Fields{"b": 2}).
+		WithField("c", 3).
+		WithFields(Fields{"d": 4}).
+		WithFields(Fields{"e": 5}).
+		Print("chained")
+	logger.WithField("a", 1).WithField("b", 2).WithField("c", 3).WithField("d", 4).WithField("e", 5).Infof("chained %s", "infof")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %s", len(lines), buffer.String())
+	}
+	for _, line := range lines {
+		var fields Fields
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatal("Unable to unmarshal the entry: ", err)
+		}
+		if fields[FieldKeyFunc] != "testing.tRunner" {
+			t.Errorf("Expected the caller to be testing.tRunner, got %v in %s", fields[FieldKeyFunc], line)
+		}
+	}
+}
+
+func TestEntryWithErrorsText(t *testing.T) {
+	entry := NewEntry(New()).WithErrors(errors.New("timeout"), errors.New("refused"))
+	entry.Message = "fan-out failed"
+
+	b, err := (&TextFormatter{DisableTimestamp: true, DisableColors: true}).Format(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "level=panic msg=\"fan-out failed\" error=\"[timeout refused]\"\n", string(b))
+
+	// WithError still sets a single error.
+	assert.Equal(t, "timeout", fmt.Sprint(entry.WithError(errors.New("timeout")).Data[ErrorKey]))
+}
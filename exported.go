@@ -56,6 +56,11 @@ func WithError(err error) *Entry {
 	return std.WithField(ErrorKey, err)
 }
 
+// WithErrors creates an entry from the standard logger and adds several errors to it, using the value defined in ErrorKey as key.
+func WithErrors(errs ...error) *Entry {
+	return std.WithErrors(errs...)
+}
+
 // WithContext creates an entry from the standard logger and adds a context to it.
 func WithContext(ctx context.Context) *Entry {
 	return std.WithContext(ctx)
@@ -80,6 +85,12 @@ func WithFields(fields Fields) *Entry {
 	return std.WithFields(fields)
 }
 
+// WithDefaults creates an entry from the standard logger and adds multiple
+// fields to it. As the entry has no fields yet, it is the same as WithFields.
+func WithDefaults(fields Fields) *Entry {
+	return std.WithDefaults(fields)
+}
+
 // WithTime creates an entry from the standard logger and overrides the time of
 // logs generated with it.
 //
@@ -89,6 +100,11 @@ func WithTime(t time.Time) *Entry {
 	return std.WithTime(t)
 }
 
+// Log logs a message at the level given as parameter on the standard logger.
+func Log(level Level, args ...interface{}) {
+	std.Log(level, args...)
+}
+
 // Trace logs a message at level Trace on the standard logger.
 func Trace(args ...interface{}) {
 	std.Trace(args...)
@@ -124,6 +140,31 @@ func Error(args ...interface{}) {
 	std.Error(args...)
 }
 
+// WithSeverity creates an entry from the standard logger and adds a syslog severity to it, using the value defined in SeverityKey as key.
+func WithSeverity(severity Severity) *Entry {
+	return std.WithSeverity(severity)
+}
+
+// LogSeverity logs a message with a syslog severity on the standard logger.
+func LogSeverity(severity Severity, args ...interface{}) {
+	std.LogSeverity(severity, args...)
+}
+
+// Emergency logs a message at level Error with the emergency severity on the standard logger.
+func Emergency(args ...interface{}) {
+	std.Emergency(args...)
+}
+
+// Alert logs a message at level Error with the alert severity on the standard logger.
+func Alert(args ...interface{}) {
+	std.Alert(args...)
+}
+
+// Critical logs a message at level Error with the critical severity on the standard logger.
+func Critical(args ...interface{}) {
+	std.Critical(args...)
+}
+
 // Panic logs a message at level Panic on the standard logger.
 func Panic(args ...interface{}) {
 	std.Panic(args...)
@@ -134,6 +175,12 @@ func Fatal(args ...interface{}) {
 	std.Fatal(args...)
 }
 
+// LogFn logs a message from a func at the level given as parameter on the
+// standard logger.
+func LogFn(level Level, fn LogFunction) {
+	std.LogFn(level, fn)
+}
+
 // TraceFn logs a message from a func at level Trace on the standard logger.
 func TraceFn(fn LogFunction) {
 	std.TraceFn(fn)
@@ -179,6 +226,11 @@ func FatalFn(fn LogFunction) {
 	std.FatalFn(fn)
 }
 
+// Logf logs a message at the level given as parameter on the standard logger.
+func Logf(level Level, format string, args ...interface{}) {
+	std.Logf(level, format, args...)
+}
+
 // Tracef logs a message at level Trace on the standard logger.
 func Tracef(format string, args ...interface{}) {
 	std.Tracef(format, args...)
@@ -224,6 +276,11 @@ func Fatalf(format string, args ...interface{}) {
 	std.Fatalf(format, args...)
 }
 
+// Logln logs a message at the level given as parameter on the standard logger.
+func Logln(level Level, args ...interface{}) {
+	std.Logln(level, args...)
+}
+
 // Traceln logs a message at level Trace on the standard logger.
 func Traceln(args ...interface{}) {
 	std.Traceln(args...)
@@ -268,3 +325,39 @@ func Panicln(args ...interface{}) {
 func Fatalln(args ...interface{}) {
 	std.Fatalln(args...)
 }
+
+// LogContext logs a message at the level given as parameter on the standard
+// logger, with the given context attached to the entry.
+func LogContext(ctx context.Context, level Level, args ...interface{}) {
+	std.LogContext(ctx, level, args...)
+}
+
+// TraceContext logs a message at level Trace on the standard logger, with the
+// given context attached to the entry.
+func TraceContext(ctx context.Context, args ...interface{}) {
+	std.TraceContext(ctx, args...)
+}
+
+// DebugContext logs a message at level Debug on the standard logger, with the
+// given context attached to the entry.
+func DebugContext(ctx context.Context, args ...interface{}) {
+	std.DebugContext(ctx, args...)
+}
+
+// InfoContext logs a message at level Info on the standard logger, with the
+// given context attached to the entry.
+func InfoContext(ctx context.Context, args ...interface{}) {
+	std.InfoContext(ctx, args...)
+}
+
+// WarnContext logs a message at level Warn on the standard logger, with the
+// given context attached to the entry.
+func WarnContext(ctx context.Context, args ...interface{}) {
+	std.WarnContext(ctx, args...)
+}
+
+// ErrorContext logs a message at level Error on the standard logger, with the
+// given context attached to the entry.
+func ErrorContext(ctx context.Context, args ...interface{}) {
+	std.ErrorContext(ctx, args...)
+}
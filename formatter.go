@@ -11,8 +11,32 @@ const (
 	FieldKeyLogrusError    = "logrus_error"
 	FieldKeyFunc           = "func"
 	FieldKeyFile           = "file"
+	FieldKeyInstanceID     = "instance_id"
 )
 
+// Timestamp formats writing the time as a number rather than with a layout,
+// for the TimestampFormat of the TextFormatter and the JSONFormatter.
+const (
+	// TimestampFormatUnix writes the seconds elapsed since the Unix epoch.
+	TimestampFormatUnix = "unix"
+	// TimestampFormatUnixMilli writes the milliseconds elapsed since the Unix
+	// epoch.
+	TimestampFormatUnixMilli = "unixmilli"
+)
+
+// formatTimestamp returns t formatted with the layout, or as a number for the
+// Unix timestamp formats.
+func formatTimestamp(t time.Time, layout string) interface{} {
+	switch layout {
+	case TimestampFormatUnix:
+		return t.Unix()
+	case TimestampFormatUnixMilli:
+		return t.UnixNano() / int64(time.Millisecond)
+	default:
+		return t.Format(layout)
+	}
+}
+
 // The Formatter interface is used to implement a custom Formatter. It takes an
 // `Entry`. It exposes all the fields, including the default ones:
 //
@@ -1,6 +1,7 @@
 package logrus
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -79,6 +80,10 @@ func BenchmarkLargeJSONFormatter(b *testing.B) {
 	doBenchmark(b, &JSONFormatter{}, largeFields)
 }
 
+func BenchmarkLargeJSONFormatterWithMarshaler(b *testing.B) {
+	doBenchmark(b, &JSONFormatter{Marshaler: json.Marshal}, largeFields)
+}
+
 func doBenchmark(b *testing.B, formatter Formatter, fields Fields) {
 	logger := New()
 
@@ -0,0 +1,90 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// GELFFormatter formats logs into GELF 1.1 JSON for Graylog. The message is
+// written as short_message, its first line, and as full_message when it has
+// several lines. The level is written as a syslog severity, see Entry.Severity, and the fields
+// are prefixed with an underscore as GELF additional fields. Entries end with
+// a newline, like with the JSONFormatter.
+type GELFFormatter struct {
+	// Host is the name of the host sending the logs. It defaults to the
+	// hostname reported by the kernel.
+	Host string
+
+	hostOnce sync.Once
+	hostname string
+}
+
+// Format renders a single log entry
+func (f *GELFFormatter) Format(entry *Entry) ([]byte, error) {
+	data := make(Fields, len(entry.Data)+8)
+	for k, v := range entry.Data {
+		if lazy, ok := v.(LazyValue); ok {
+			v = lazy()
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[gelfFieldKey(k)] = v
+	}
+
+	shortMessage := entry.Message
+	if i := strings.IndexByte(shortMessage, '\n'); i >= 0 {
+		shortMessage = shortMessage[:i]
+	}
+
+	data["version"] = "1.1"
+	data["host"] = f.host()
+	data["short_message"] = shortMessage
+	if shortMessage != entry.Message {
+		data["full_message"] = entry.Message
+	}
+	data["timestamp"] = float64(entry.Time.UnixNano()/int64(1e6)) / 1e3
+	data["level"] = int(entry.Severity())
+	if entry.err != "" {
+		data[gelfFieldKey(FieldKeyLogrusError)] = entry.err
+	}
+	if entry.HasCaller() {
+		data[gelfFieldKey(FieldKeyFunc)] = entry.Caller.Function
+		data[gelfFieldKey(FieldKeyFile)] = fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+	}
+
+	var b *bytes.Buffer
+	if entry.Buffer != nil {
+		b = entry.Buffer
+	} else {
+		b = &bytes.Buffer{}
+	}
+
+	if err := json.NewEncoder(b).Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to GELF, %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+func (f *GELFFormatter) host() string {
+	if f.Host != "" {
+		return f.Host
+	}
+	f.hostOnce.Do(func() {
+		f.hostname, _ = os.Hostname()
+	})
+	return f.hostname
+}
+
+// gelfFieldKey returns the GELF additional field key of a field. The `_id`
+// key is reserved by GELF, so the `id` field is written as `__id`.
+func gelfFieldKey(key string) string {
+	if key == "id" {
+		return "__id"
+	}
+	return "_" + key
+}
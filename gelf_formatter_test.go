@@ -0,0 +1,66 @@
+package logrus
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELFFormatter(t *testing.T) {
+	formatter := &GELFFormatter{Host: "web-1"}
+
+	entry := WithFields(Fields{
+		"user":  "alice",
+		"id":    42,
+		"error": errors.New("wild walrus"),
+	})
+	entry.Time = time.Date(2021, 6, 1, 12, 30, 45, 123456789, time.UTC)
+	entry.Level = WarnLevel
+	entry.Message = "request failed\nstack trace"
+
+	b, err := formatter.Format(entry)
+	require.NoError(t, err)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &data))
+	assert.Equal(t, map[string]interface{}{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": "request failed",
+		"full_message":  "request failed\nstack trace",
+		"timestamp":     1622550645.123,
+		"level":         4.0,
+		"_user":         "alice",
+		"__id":          42.0,
+		"_error":        "wild walrus",
+	}, data)
+}
+
+func TestGELFFormatterSingleLineMessage(t *testing.T) {
+	formatter := &GELFFormatter{}
+
+	b, err := formatter.Format(&Entry{Level: ErrorLevel, Message: "oops", Data: Fields{}})
+	require.NoError(t, err)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &data))
+	assert.Equal(t, "oops", data["short_message"])
+	assert.NotContains(t, data, "full_message")
+	assert.Equal(t, 3.0, data["level"])
+	assert.NotEmpty(t, data["host"], "the host must default to the hostname")
+}
+
+func TestGELFFormatterSeverity(t *testing.T) {
+	formatter := &GELFFormatter{}
+
+	b, err := formatter.Format(&Entry{Level: ErrorLevel, Message: "oops", Data: Fields{SeverityKey: SeverityAlert}})
+	require.NoError(t, err)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &data))
+	assert.Equal(t, 1.0, data["level"], "the severity set on the entry must override the one of its level")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"sync"
 	"testing"
 
@@ -207,6 +208,29 @@ func TestAddHookRace2(t *testing.T) {
 	}
 }
 
+func TestReplaceHooksWhileLogging(t *testing.T) {
+	var wg sync.WaitGroup
+	logger := New()
+	logger.Out = &bytes.Buffer{}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.AddHook(new(ErrorHook))
+			old := logger.ReplaceHooks(make(LevelHooks))
+			old.Clear()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			logger.Error("test")
+		}
+	}()
+	wg.Wait()
+}
+
 type HookCallFunc struct {
 	F func()
 }
@@ -232,3 +256,119 @@ func TestHookFireOrder(t *testing.T) {
 	}
 	require.Equal(t, []string{"first hook", "second hook", "third hook"}, checkers)
 }
+
+type PostFormatRecorderHook struct {
+	Formatted [][]byte
+}
+
+func (h *PostFormatRecorderHook) Levels() []Level {
+	return AllLevels
+}
+
+func (h *PostFormatRecorderHook) Fire(e *Entry) error {
+	return nil
+}
+
+func (h *PostFormatRecorderHook) PostFormat(e *Entry, formatted []byte) error {
+	h.Formatted = append(h.Formatted, append([]byte(nil), formatted...))
+	return nil
+}
+
+func TestPostFormatHookReceivesWrittenBytes(t *testing.T) {
+	var buffer bytes.Buffer
+	hook := new(PostFormatRecorderHook)
+
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = &TextFormatter{DisableColors: true}
+	logger.Hooks.Add(hook)
+
+	logger.WithField("key", "value").Info("first")
+	require.Len(t, hook.Formatted, 1)
+	assert.Equal(t, buffer.Bytes(), hook.Formatted[0])
+	assert.Equal(t, byte('\n'), hook.Formatted[0][len(hook.Formatted[0])-1])
+
+	buffer.Reset()
+	logger.Warn("second")
+	require.Len(t, hook.Formatted, 2)
+	assert.Equal(t, buffer.Bytes(), hook.Formatted[1])
+}
+
+type DropHook struct {
+	Drop bool
+}
+
+func (h *DropHook) Levels() []Level {
+	return AllLevels
+}
+
+func (h *DropHook) Fire(e *Entry) error {
+	return nil
+}
+
+func (h *DropHook) Filter(e *Entry) (bool, error) {
+	return !h.Drop, nil
+}
+
+func TestFilterHookCanDropEntry(t *testing.T) {
+	var buffer bytes.Buffer
+	drop := &DropHook{Drop: true}
+	after := new(TestHook)
+
+	logger := New()
+	logger.Out = &buffer
+	logger.AddHook(drop)
+	logger.AddHook(after)
+
+	logger.Info("dropped")
+	assert.Empty(t, buffer.String())
+	assert.True(t, after.Fired, "the hooks after a dropping filter must still fire")
+
+	drop.Drop = false
+	logger.Info("kept")
+	assert.Contains(t, buffer.String(), "kept")
+}
+
+func TestLevelHooksHelpers(t *testing.T) {
+	hooks := LevelHooks{}
+	assert.Empty(t, hooks.Levels())
+	assert.Equal(t, 0, hooks.Len(ErrorLevel))
+
+	hooks.Add(new(ErrorHook))
+	hooks.Add(&SingleLevelModifyHook{})
+	hooks.Add(new(ErrorHook))
+	assert.Equal(t, []Level{ErrorLevel, InfoLevel}, hooks.Levels())
+	assert.Equal(t, 2, hooks.Len(ErrorLevel))
+	assert.Equal(t, 1, hooks.Len(InfoLevel))
+	assert.Equal(t, 0, hooks.Len(DebugLevel))
+
+	hooks.Clear()
+	assert.Empty(t, hooks.Levels())
+	assert.Equal(t, 0, hooks.Len(ErrorLevel))
+}
+
+func TestFuncHooks(t *testing.T) {
+	var all, some []Level
+	logger := New()
+	logger.Out = ioutil.Discard
+	logger.ExitFunc = func(int) {}
+	logger.SetLevel(TraceLevel)
+	logger.Hooks.AddForAll(func(e *Entry) error {
+		all = append(all, e.Level)
+		return nil
+	})
+	logger.Hooks.AddForLevels([]Level{ErrorLevel, WarnLevel}, func(e *Entry) error {
+		some = append(some, e.Level)
+		return nil
+	})
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	logger.Fatal("fatal")
+	assert.Panics(t, func() { logger.Panic("panic") })
+
+	assert.Equal(t, []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel}, all)
+	assert.Equal(t, []Level{WarnLevel, ErrorLevel}, some)
+}
@@ -21,12 +21,115 @@ func (hooks LevelHooks) Add(hook Hook) {
 	}
 }
 
+// AddForAll adds a hook calling fn for all the levels, without implementing
+// the `Hook` interface, e.g. `log.Hooks.AddForAll(func(e *Entry) error {...})`.
+func (hooks LevelHooks) AddForAll(fn func(*Entry) error) {
+	hooks.AddForLevels(AllLevels, fn)
+}
+
+// AddForLevels adds a hook calling fn for the passed levels.
+func (hooks LevelHooks) AddForLevels(levels []Level, fn func(*Entry) error) {
+	hooks.Add(&funcHook{levels: levels, fire: fn})
+}
+
+// funcHook adapts a function to the `Hook` interface.
+type funcHook struct {
+	levels []Level
+	fire   func(*Entry) error
+}
+
+func (hook *funcHook) Levels() []Level {
+	return hook.levels
+}
+
+func (hook *funcHook) Fire(entry *Entry) error {
+	return hook.fire(entry)
+}
+
+// Levels returns the levels having at least one hook, from the most to the
+// least severe.
+func (hooks LevelHooks) Levels() []Level {
+	var levels []Level
+	for _, level := range AllLevels {
+		if len(hooks[level]) > 0 {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// Len returns the number of hooks fired for the passed level.
+func (hooks LevelHooks) Len(level Level) int {
+	return len(hooks[level])
+}
+
+// Clear removes all the hooks. It is not safe to call on the hooks of a logger
+// in use, see `Logger.ReplaceHooks` to remove them while logging.
+func (hooks LevelHooks) Clear() {
+	for level := range hooks {
+		delete(hooks, level)
+	}
+}
+
 // Fire all the hooks for the passed level. Used by `entry.log` to fire
 // appropriate hooks for a log entry.
 func (hooks LevelHooks) Fire(level Level, entry *Entry) error {
+	_, err := hooks.fire(level, entry)
+	return err
+}
+
+// fire fires the hooks for the passed level, calling Filter in place of Fire
+// for filter hooks, and reports whether the entry must be written.
+func (hooks LevelHooks) fire(level Level, entry *Entry) (bool, error) {
+	keep := true
 	for _, hook := range hooks[level] {
+		if filter, ok := hook.(FilterHook); ok {
+			k, err := filter.Filter(entry)
+			keep = keep && k
+			if err != nil {
+				return keep, err
+			}
+			continue
+		}
 		if err := hook.Fire(entry); err != nil {
-			return err
+			return keep, err
+		}
+	}
+
+	return keep, nil
+}
+
+// FilterHook is an optional interface for hooks deciding whether an entry is
+// written, e.g. to drop noisy entries. Filter is called in place of Fire, in
+// the order the hooks were added, and may modify the entry, e.g. to redact
+// secrets. When it returns false the entry is not written to the logger
+// output, but the remaining hooks still fire.
+type FilterHook interface {
+	Hook
+	Filter(*Entry) (keep bool, err error)
+}
+
+// PostFormatHook is an optional interface for hooks that need the exact bytes
+// written to the logger output, e.g. for auditing. PostFormat is called for
+// the levels returned from `Levels()` after the entry has been formatted and
+// written, with the same bytes that `Out` received (including the trailing
+// newline).
+//
+// The formatted slice is backed by a pooled buffer that is reused once
+// PostFormat returns: a hook that retains it must make its own copy.
+type PostFormatHook interface {
+	Hook
+	PostFormat(entry *Entry, formatted []byte) error
+}
+
+// FirePostFormat calls PostFormat on all the hooks implementing
+// PostFormatHook for the passed level.
+func (hooks LevelHooks) FirePostFormat(level Level, entry *Entry, formatted []byte) error {
+	for _, hook := range hooks[level] {
+		if pf, ok := hook.(PostFormatHook); ok {
+			if err := pf.PostFormat(entry, formatted); err != nil {
+				return err
+			}
 		}
 	}
 
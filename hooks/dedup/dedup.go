@@ -0,0 +1,93 @@
+package dedup
+
+import (
+	"reflect"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RepeatedKey is the key of the field holding the number of suppressed
+// entries on the summary entries.
+const RepeatedKey = "repeated"
+
+// Hook is a filter hook collapsing identical consecutive entries, e.g. during
+// retry storms. An entry with the same level, message and fields as the
+// previous one is dropped, and a "previous message repeated N times" entry is
+// logged before the next distinct entry, or when the hook is flushed, e.g.
+// by Logger.Exit. The hook is safe for concurrent use.
+type Hook struct {
+	mu       sync.Mutex
+	previous *log.Entry
+	repeated int
+}
+
+// repeatCount marks the summary entries, which the hook lets through.
+type repeatCount int
+
+// NewHook returns a hook collapsing identical consecutive entries.
+func NewHook() *Hook {
+	return &Hook{}
+}
+
+// Filter drops the entry when it repeats the previous one.
+func (hook *Hook) Filter(entry *log.Entry) (bool, error) {
+	if _, ok := entry.Data[RepeatedKey].(repeatCount); ok {
+		return true, nil
+	}
+
+	hook.mu.Lock()
+	if hook.previous != nil && sameEntry(hook.previous, entry) {
+		hook.repeated++
+		hook.mu.Unlock()
+		return false, nil
+	}
+	previous, repeated := hook.previous, hook.repeated
+	hook.previous, hook.repeated = entry.Dup(), 0
+	hook.previous.Level, hook.previous.Message = entry.Level, entry.Message
+	hook.mu.Unlock()
+
+	logRepeated(previous, repeated)
+	return true, nil
+}
+
+// Flush logs the number of times the last entry was repeated, if any.
+func (hook *Hook) Flush() error {
+	hook.mu.Lock()
+	previous, repeated := hook.previous, hook.repeated
+	hook.repeated = 0
+	hook.mu.Unlock()
+
+	logRepeated(previous, repeated)
+	return nil
+}
+
+// Fire does nothing, entries are only deduplicated as a filter hook.
+func (hook *Hook) Fire(entry *log.Entry) error {
+	return nil
+}
+
+// Levels returns all the levels, entries are deduplicated whatever their
+// level.
+func (hook *Hook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func sameEntry(a, b *log.Entry) bool {
+	return a.Level == b.Level && a.Message == b.Message && reflect.DeepEqual(a.Data, b.Data)
+}
+
+// logRepeated logs the summary of the repetitions of the previous entry, at
+// its level but no more severe than the error level, so that a summary never
+// exits or panics.
+func logRepeated(previous *log.Entry, repeated int) {
+	if previous == nil || repeated == 0 {
+		return
+	}
+	level := previous.Level
+	if level < log.ErrorLevel {
+		level = log.ErrorLevel
+	}
+	previous.Logger.WithField(RepeatedKey, repeatCount(repeated)).
+		Logf(level, "previous message repeated %d times", repeated)
+}
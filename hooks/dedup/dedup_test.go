@@ -0,0 +1,59 @@
+package dedup
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatedEntriesAreCollapsed(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := log.New()
+	logger.Out = &buffer
+	logger.Formatter = &log.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	logger.AddHook(NewHook())
+
+	for i := 0; i < 5; i++ {
+		logger.WithField("attempt", "retry").Error("connection refused")
+	}
+	logger.Info("connected")
+
+	assert.Equal(t, "level=error msg=\"connection refused\" attempt=retry\n"+
+		"level=error msg=\"previous message repeated 4 times\" repeated=4\n"+
+		"level=info msg=connected\n", buffer.String())
+}
+
+func TestEntriesWithDifferentFieldsAreKept(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := log.New()
+	logger.Out = &buffer
+	logger.Formatter = &log.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	logger.AddHook(NewHook())
+
+	logger.WithField("attempt", 1).Warn("retrying")
+	logger.WithField("attempt", 2).Warn("retrying")
+	logger.WithField("attempt", 2).Info("retrying")
+
+	assert.Equal(t, "level=warning msg=retrying attempt=1\n"+
+		"level=warning msg=retrying attempt=2\n"+
+		"level=info msg=retrying attempt=2\n", buffer.String())
+}
+
+func TestFlushLogsPendingRepetitions(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := log.New()
+	logger.Out = &buffer
+	logger.Formatter = &log.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	hook := NewHook()
+	logger.AddHook(hook)
+
+	logger.Warn("disk almost full")
+	logger.Warn("disk almost full")
+	assert.NoError(t, hook.Flush())
+	assert.NoError(t, hook.Flush())
+
+	assert.Equal(t, "level=warning msg=\"disk almost full\"\n"+
+		"level=warning msg=\"previous message repeated 1 times\" repeated=1\n", buffer.String())
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Hook counts the entries per level, e.g. to alarm on the error rate. It is
+// safe for concurrent use.
+type Hook struct {
+	// Accessed atomically, kept first for 64-bit alignment on 32-bit platforms.
+	counts [log.TraceLevel + 1]uint64
+
+	onIncrement func(level log.Level, count uint64)
+}
+
+// NewHook returns a hook counting the entries per level. When onIncrement is
+// not nil, it is called with the new count after each increment, e.g. to
+// update a Prometheus counter. It is called from the logging goroutines and
+// must be safe for concurrent use.
+func NewHook(onIncrement func(level log.Level, count uint64)) *Hook {
+	return &Hook{onIncrement: onIncrement}
+}
+
+// Fire increments the counter of the entry level.
+func (hook *Hook) Fire(entry *log.Entry) error {
+	if int(entry.Level) >= len(hook.counts) {
+		return nil
+	}
+	count := atomic.AddUint64(&hook.counts[entry.Level], 1)
+	if hook.onIncrement != nil {
+		hook.onIncrement(entry.Level, count)
+	}
+	return nil
+}
+
+// Levels returns all the levels, entries are counted whatever their level.
+func (hook *Hook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Count returns the number of entries of the given level.
+func (hook *Hook) Count(level log.Level) uint64 {
+	if int(level) >= len(hook.counts) {
+		return 0
+	}
+	return atomic.LoadUint64(&hook.counts[level])
+}
+
+// Counts returns the number of entries of each level.
+func (hook *Hook) Counts() map[log.Level]uint64 {
+	counts := make(map[log.Level]uint64, len(hook.counts))
+	for _, level := range log.AllLevels {
+		counts[level] = hook.Count(level)
+	}
+	return counts
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountsPerLevel(t *testing.T) {
+	var increments uint64
+	hook := NewHook(func(log.Level, uint64) {
+		atomic.AddUint64(&increments, 1)
+	})
+
+	logger := log.New()
+	logger.Out = ioutil.Discard
+	logger.SetLevel(log.DebugLevel)
+	logger.AddHook(hook)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Debug("debug")
+				logger.Info("info")
+				logger.Info("info")
+				logger.Error("error")
+				logger.Trace("disabled")
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[log.Level]uint64{
+		log.PanicLevel: 0,
+		log.FatalLevel: 0,
+		log.ErrorLevel: 1000,
+		log.WarnLevel:  0,
+		log.InfoLevel:  2000,
+		log.DebugLevel: 1000,
+		log.TraceLevel: 0,
+	}, hook.Counts())
+	assert.Equal(t, uint64(2000), hook.Count(log.InfoLevel))
+	assert.Equal(t, uint64(4000), atomic.LoadUint64(&increments))
+}
@@ -0,0 +1,59 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultReplacement is the value written in place of redacted fields when
+// the hook Replacement is empty.
+const DefaultReplacement = "[REDACTED]"
+
+// Hook is a filter hook replacing the values of sensitive fields, such as
+// passwords or tokens, before entries are written. As hooks fire in the order
+// they were added, add it before any hook shipping the entries elsewhere.
+type Hook struct {
+	// Keys are the names of the fields to redact, compared case insensitively.
+	Keys []string
+	// Pattern, when set, also redacts the fields whose name matches it.
+	Pattern *regexp.Regexp
+	// Replacement is the value of the redacted fields, DefaultReplacement if
+	// empty.
+	Replacement string
+}
+
+// Filter redacts the sensitive fields of the entry. It never drops the entry.
+func (hook *Hook) Filter(entry *log.Entry) (bool, error) {
+	replacement := hook.Replacement
+	if replacement == "" {
+		replacement = DefaultReplacement
+	}
+	for k := range entry.Data {
+		if hook.sensitive(k) {
+			entry.Data[k] = replacement
+		}
+	}
+	return true, nil
+}
+
+// Fire redacts the entry, for callers which don't know about filter hooks.
+func (hook *Hook) Fire(entry *log.Entry) error {
+	_, err := hook.Filter(entry)
+	return err
+}
+
+// Levels returns all the levels, entries are redacted whatever their level.
+func (hook *Hook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (hook *Hook) sensitive(key string) bool {
+	for _, k := range hook.Keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return hook.Pattern != nil && hook.Pattern.MatchString(key)
+}
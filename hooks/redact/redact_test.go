@@ -0,0 +1,40 @@
+package redact
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactsSensitiveFields(t *testing.T) {
+	var buffer bytes.Buffer
+
+	logger := log.New()
+	logger.Out = &buffer
+	logger.Formatter = &log.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	logger.AddHook(&Hook{
+		Keys:    []string{"password"},
+		Pattern: regexp.MustCompile(`(?i)token$`),
+	})
+
+	entry := logger.WithFields(log.Fields{
+		"user":         "alice",
+		"Password":     "hunter2",
+		"access_token": "abcd",
+	})
+	entry.Info("login")
+
+	assert.Equal(t, "level=info msg=login Password=\"[REDACTED]\" access_token=\"[REDACTED]\" user=alice\n", buffer.String())
+	assert.Equal(t, "hunter2", entry.Data["Password"], "the logged entry must not be modified")
+}
+
+func TestRedactsWithCustomReplacement(t *testing.T) {
+	entry := log.WithField("secret", "s3cr3t")
+	hook := &Hook{Keys: []string{"secret"}, Replacement: "***"}
+
+	assert.NoError(t, hook.Fire(entry))
+	assert.Equal(t, "***", entry.Data["secret"])
+}
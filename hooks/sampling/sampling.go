@@ -0,0 +1,71 @@
+package sampling
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Hook is a filter hook throttling repetitive entries. Within each interval,
+// the first First entries with a given level and message are logged, then
+// only one in Thereafter. The counters are reset at the end of each interval.
+// The hook is safe for concurrent use.
+type Hook struct {
+	interval   time.Duration
+	first      uint64
+	thereafter uint64
+
+	mu     sync.Mutex
+	counts map[sampleKey]uint64
+	reset  time.Time
+	now    func() time.Time
+}
+
+type sampleKey struct {
+	level   log.Level
+	message string
+}
+
+// NewHook returns a hook logging, per level and message, the first entries
+// of each interval, then one in thereafter. When thereafter is zero, all the
+// entries after the first ones are dropped until the end of the interval.
+func NewHook(interval time.Duration, first, thereafter int) *Hook {
+	return &Hook{
+		interval:   interval,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		counts:     make(map[sampleKey]uint64),
+		now:        time.Now,
+	}
+}
+
+// Filter tells whether the entry is sampled in.
+func (hook *Hook) Filter(entry *log.Entry) (bool, error) {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if now := hook.now(); !now.Before(hook.reset) {
+		hook.counts = make(map[sampleKey]uint64, len(hook.counts))
+		hook.reset = now.Add(hook.interval)
+	}
+
+	key := sampleKey{level: entry.Level, message: entry.Message}
+	n := hook.counts[key] + 1
+	hook.counts[key] = n
+
+	if n <= hook.first {
+		return true, nil
+	}
+	return hook.thereafter > 0 && (n-hook.first)%hook.thereafter == 0, nil
+}
+
+// Fire does nothing, entries are only sampled as a filter hook.
+func (hook *Hook) Fire(entry *log.Entry) error {
+	return nil
+}
+
+// Levels returns all the levels, entries are sampled whatever their level.
+func (hook *Hook) Levels() []log.Level {
+	return log.AllLevels
+}
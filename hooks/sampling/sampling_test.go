@@ -0,0 +1,76 @@
+package sampling
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLogger(hook *Hook) (*log.Logger, *bytes.Buffer) {
+	var buffer bytes.Buffer
+
+	logger := log.New()
+	logger.Out = &buffer
+	logger.Formatter = &log.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	logger.AddHook(hook)
+	return logger, &buffer
+}
+
+func TestSamplesRepetitiveEntries(t *testing.T) {
+	logger, buffer := newLogger(NewHook(time.Hour, 10, 100))
+
+	for i := 0; i < 1000; i++ {
+		logger.Error("hot path failed")
+	}
+	logger.Warn("hot path failed")
+	logger.Error("another message")
+
+	// 10 first ones, then the 110th, 210th... 910th.
+	assert.Equal(t, 19, strings.Count(buffer.String(), "level=error msg=\"hot path failed\""))
+	assert.Contains(t, buffer.String(), "level=warning msg=\"hot path failed\"")
+	assert.Contains(t, buffer.String(), "level=error msg=\"another message\"")
+}
+
+func TestSamplingResetsAtIntervalBoundary(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	hook := NewHook(time.Second, 2, 0)
+	hook.now = func() time.Time { return now }
+	logger, buffer := newLogger(hook)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("tick")
+	}
+	now = now.Add(999 * time.Millisecond)
+	logger.Info("tick")
+	assert.Equal(t, 2, strings.Count(buffer.String(), "msg=tick"))
+
+	now = now.Add(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		logger.Info("tick")
+	}
+	assert.Equal(t, 4, strings.Count(buffer.String(), "msg=tick"))
+}
+
+func TestSamplingIsSafeForConcurrentUse(t *testing.T) {
+	logger, buffer := newLogger(NewHook(time.Hour, 5, 10))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				logger.Info("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 5 first ones, then one in 10 of the remaining 995.
+	assert.Equal(t, 5+99, strings.Count(buffer.String(), "msg=concurrent"))
+}
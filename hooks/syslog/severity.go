@@ -0,0 +1,35 @@
+// +build !windows,!nacl,!plan9
+
+package syslog
+
+import (
+	"log/syslog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelMap maps logrus levels to syslog severities.
+type LevelMap map[logrus.Level]syslog.Priority
+
+// DefaultLevelMap is the mapping used by Severity, the one of Level.Severity.
+var DefaultLevelMap = func() LevelMap {
+	m := make(LevelMap, len(logrus.AllLevels))
+	for _, level := range logrus.AllLevels {
+		m[level] = syslog.Priority(level.Severity())
+	}
+	return m
+}()
+
+// Severity returns the syslog severity of the level, LOG_DEBUG if the level
+// is not in the map.
+func (m LevelMap) Severity(level logrus.Level) syslog.Priority {
+	if severity, ok := m[level]; ok {
+		return severity
+	}
+	return syslog.LOG_DEBUG
+}
+
+// Severity returns the syslog severity of the level in DefaultLevelMap.
+func Severity(level logrus.Level) syslog.Priority {
+	return DefaultLevelMap.Severity(level)
+}
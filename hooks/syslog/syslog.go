@@ -32,21 +32,29 @@ func (hook *SyslogHook) Fire(entry *logrus.Entry) error {
 		return err
 	}
 
-	switch entry.Level {
-	case logrus.PanicLevel:
-		return hook.Writer.Crit(line)
-	case logrus.FatalLevel:
+	return hook.writeSeverity(entry.Severity(), line)
+}
+
+// writeSeverity writes the line of an entry with its syslog severity, the one
+// set with WithSeverity or else the one of its level.
+func (hook *SyslogHook) writeSeverity(severity logrus.Severity, line string) error {
+	switch severity {
+	case logrus.SeverityEmergency:
+		return hook.Writer.Emerg(line)
+	case logrus.SeverityAlert:
+		return hook.Writer.Alert(line)
+	case logrus.SeverityCritical:
 		return hook.Writer.Crit(line)
-	case logrus.ErrorLevel:
+	case logrus.SeverityError:
 		return hook.Writer.Err(line)
-	case logrus.WarnLevel:
+	case logrus.SeverityWarning:
 		return hook.Writer.Warning(line)
-	case logrus.InfoLevel:
+	case logrus.SeverityNotice:
+		return hook.Writer.Notice(line)
+	case logrus.SeverityInfo:
 		return hook.Writer.Info(line)
-	case logrus.DebugLevel, logrus.TraceLevel:
-		return hook.Writer.Debug(line)
 	default:
-		return nil
+		return hook.Writer.Debug(line)
 	}
 }
 
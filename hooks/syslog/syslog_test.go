@@ -3,8 +3,12 @@
 package syslog
 
 import (
+	"io/ioutil"
 	"log/syslog"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -27,3 +31,75 @@ func TestLocalhostAddAndPrint(t *testing.T) {
 
 	log.Info("Congratulations!")
 }
+
+func TestSeverity(t *testing.T) {
+	for level, expected := range map[logrus.Level]syslog.Priority{
+		logrus.PanicLevel: syslog.LOG_EMERG,
+		logrus.FatalLevel: syslog.LOG_CRIT,
+		logrus.ErrorLevel: syslog.LOG_ERR,
+		logrus.WarnLevel:  syslog.LOG_WARNING,
+		logrus.InfoLevel:  syslog.LOG_INFO,
+		logrus.DebugLevel: syslog.LOG_DEBUG,
+		logrus.TraceLevel: syslog.LOG_DEBUG,
+		logrus.Level(42):  syslog.LOG_DEBUG,
+	} {
+		if severity := Severity(level); severity != expected {
+			t.Errorf("expected severity %d for level %v, got %d", expected, level, severity)
+		}
+	}
+}
+
+func TestCustomLevelMap(t *testing.T) {
+	m := LevelMap{logrus.PanicLevel: syslog.LOG_ALERT}
+
+	if severity := m.Severity(logrus.PanicLevel); severity != syslog.LOG_ALERT {
+		t.Errorf("expected severity %d, got %d", syslog.LOG_ALERT, severity)
+	}
+	if severity := m.Severity(logrus.ErrorLevel); severity != syslog.LOG_DEBUG {
+		t.Errorf("expected severity %d for a level not in the map, got %d", syslog.LOG_DEBUG, severity)
+	}
+}
+
+func TestFireSeverities(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("Unable to listen on UDP:", err)
+	}
+	defer conn.Close()
+
+	hook, err := NewSyslogHook("udp", conn.LocalAddr().String(), syslog.LOG_INFO|syslog.LOG_USER, "test")
+	if err != nil {
+		t.Fatal("Unable to connect to the test syslog:", err)
+	}
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.ExitFunc = func(int) {}
+	log.Hooks.Add(hook)
+
+	for _, tc := range []struct {
+		log      func()
+		priority string
+	}{
+		{func() { log.Alert("disk gone") }, "<9>"},
+		{func() { log.Emergency("datacenter on fire") }, "<8>"},
+		{func() { log.Error("no alias") }, "<11>"},
+		{func() {
+			defer func() { recover() }()
+			log.Panic("boom")
+		}, "<8>"},
+		{func() { log.Fatal("bye") }, "<10>"},
+		{func() { log.Warn("careful") }, "<12>"},
+	} {
+		tc.log()
+
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal("Unable to read the syslog message:", err)
+		}
+		if msg := string(buf[:n]); !strings.HasPrefix(msg, tc.priority) {
+			t.Errorf("expected priority %s, got %q", tc.priority, msg)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package test_test
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// chargeCard stands for the code under test, which logs through the logger
+// it is given.
+func chargeCard(log logrus.FieldLogger, amount int) {
+	if amount > 100 {
+		log.WithField("amount", amount).Warn("charge above the limit")
+	}
+}
+
+// An example on how to assert on the entries logged by the code under test
+func ExampleNewNullLogger() {
+	logger, hook := test.NewNullLogger()
+
+	chargeCard(logger, 250)
+
+	entry := hook.LastEntry()
+	fmt.Println(len(hook.AllEntries()))
+	fmt.Println(entry.Level)
+	fmt.Println(entry.Message)
+	fmt.Println(entry.Data["amount"])
+
+	hook.Reset()
+	fmt.Println(hook.LastEntry() == nil)
+	// Output:
+	// 1
+	// warning
+	// charge above the limit
+	// 250
+	// true
+}
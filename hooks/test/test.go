@@ -70,7 +70,9 @@ func (t *Hook) LastEntry() *logrus.Entry {
 	return &t.Entries[i]
 }
 
-// AllEntries returns all entries that were logged.
+// AllEntries returns all entries that were logged, in a new slice which is
+// safe to use while other goroutines are logging. It is the accessor of the
+// Entries field, which takes the Entries name.
 func (t *Hook) AllEntries() []*logrus.Entry {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
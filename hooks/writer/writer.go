@@ -10,12 +10,32 @@ import (
 type Hook struct {
 	Writer    io.Writer
 	LogLevels []log.Level
+
+	// Formatter, when set, formats the entries in place of the logger
+	// formatter, e.g. to write JSON to a file while the logger writes text.
+	Formatter log.Formatter
+}
+
+// NewWriterHook creates a hook writing the logs of the passed levels to w,
+// formatted with formatter.
+func NewWriterHook(w io.Writer, formatter log.Formatter, levels []log.Level) *Hook {
+	return &Hook{
+		Writer:    w,
+		LogLevels: levels,
+		Formatter: formatter,
+	}
 }
 
 // Fire will be called when some logging function is called with current hook
 // It will format log entry to string and write it to appropriate writer
 func (hook *Hook) Fire(entry *log.Entry) error {
-	line, err := entry.Bytes()
+	var line []byte
+	var err error
+	if hook.Formatter != nil {
+		line, err = hook.Formatter.Format(entry)
+	} else {
+		line, err = entry.Bytes()
+	}
 	if err != nil {
 		return err
 	}
@@ -36,3 +36,17 @@ func TestDifferentLevelsGoToDifferentWriters(t *testing.T) {
 	assert.Equal(t, a.String(), "level=warning msg=\"send to a\"\n")
 	assert.Equal(t, b.String(), "level=info msg=\"send to b\"\n")
 }
+
+func TestWriterHookWithItsOwnFormatter(t *testing.T) {
+	var console, file bytes.Buffer
+
+	logger := log.New()
+	logger.Out = &console
+	logger.Formatter = &log.TextFormatter{DisableTimestamp: true, DisableColors: true}
+	logger.AddHook(NewWriterHook(&file, &log.JSONFormatter{DisableTimestamp: true}, log.AllLevels))
+
+	logger.WithField("user", "alice").Info("logged in")
+
+	assert.Equal(t, "level=info msg=\"logged in\" user=alice\n", console.String())
+	assert.JSONEq(t, `{"level":"info","msg":"logged in","user":"alice"}`, file.String())
+}
@@ -3,6 +3,7 @@ package testutils
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"testing"
@@ -39,20 +40,40 @@ func LogAndAssertText(t *testing.T, log func(*Logger), assertions func(fields ma
 
 	log(logger)
 
+	fields, err := ParseText(strings.TrimRight(buffer.String(), "\n"))
+	require.NoError(t, err)
+	assertions(fields)
+}
+
+// ParseText parses a line written by the TextFormatter, without colors, into
+// its key value pairs. Quoted values are unquoted.
+func ParseText(line string) (map[string]string, error) {
 	fields := make(map[string]string)
-	for _, kv := range strings.Split(strings.TrimRight(buffer.String(), "\n"), " ") {
-		if !strings.Contains(kv, "=") {
-			continue
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			break
 		}
-		kvArr := strings.Split(kv, "=")
-		key := strings.TrimSpace(kvArr[0])
-		val := kvArr[1]
-		if kvArr[1][0] == '"' {
-			var err error
-			val, err = strconv.Unquote(val)
-			require.NoError(t, err)
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var val string
+		if strings.HasPrefix(line, `"`) {
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				return nil, fmt.Errorf("value of %q: %w", key, err)
+			}
+			line = line[len(quoted):]
+			if val, err = strconv.Unquote(quoted); err != nil {
+				return nil, fmt.Errorf("value of %q: %w", key, err)
+			}
+		} else if sp := strings.IndexByte(line, ' '); sp >= 0 {
+			val, line = line[:sp], line[sp:]
+		} else {
+			val, line = line, ""
 		}
 		fields[key] = val
 	}
-	assertions(fields)
+	return fields, nil
 }
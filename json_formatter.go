@@ -3,7 +3,9 @@ package logrus
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"runtime"
 )
 
@@ -26,6 +28,8 @@ type JSONFormatter struct {
 	// The format to use is the same than for time.Format or time.Parse from the standard
 	// library.
 	// The standard Library already provides a set of predefined format.
+	// TimestampFormatUnix and TimestampFormatUnixMilli write Unix timestamps
+	// as JSON numbers.
 	TimestampFormat string
 
 	// DisableTimestamp allows disabling automatic timestamps in output
@@ -57,12 +61,46 @@ type JSONFormatter struct {
 
 	// PrettyPrint will indent all json logs
 	PrettyPrint bool
+
+	// DisableNewline disables the newline written after each entry, e.g. for
+	// transports framing the messages themselves.
+	DisableNewline bool
+
+	// Indent is the indentation used by PrettyPrint, two spaces by default.
+	Indent string
+
+	// Marshaler, when set, marshals the entries in place of encoding/json,
+	// e.g. `jsoniter.ConfigCompatibleWithStandardLibrary.Marshal`. Its output
+	// is indented when PrettyPrint is set, but DisableHTMLEscape is ignored:
+	// HTML characters are escaped as the marshaler does.
+	Marshaler func(v interface{}) ([]byte, error)
+
+	// LevelEncoder, when set, encodes the level value in place of its name,
+	// e.g. to emit the numeric severities of a log backend. It composes with
+	// FieldMap, which sets the key of the level.
+	LevelEncoder func(Level) interface{}
+
+	// ErrorOnMarshalFailure makes Format fail when a field value can't be
+	// marshaled. By default such values are replaced by a placeholder string
+	// and the rest of the entry is still written.
+	ErrorOnMarshalFailure bool
+
+	// CallStackField, when set, is the key under which the stack trace of the
+	// error added with WithError is emitted, as a list of "function file:line"
+	// frames, or the list of the stack traces of the errors added with
+	// WithErrors. The stack trace is taken from the innermost error of the
+	// `errors.Unwrap` chain having a `StackTrace()` method returning a slice of
+	// program counters, such as the errors of github.com/pkg/errors.
+	CallStackField string
 }
 
 // Format renders a single log entry
 func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
 	data := make(Fields, len(entry.Data)+4)
 	for k, v := range entry.Data {
+		if lazy, ok := v.(LazyValue); ok {
+			v = lazy()
+		}
 		switch v := v.(type) {
 		case error:
 			// Otherwise errors are ignored by `encoding/json`
@@ -73,6 +111,26 @@ func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
 		}
 	}
 
+	if f.CallStackField != "" {
+		switch err := entry.Data[ErrorKey].(type) {
+		case error:
+			if frames := errorStackTrace(err); frames != nil {
+				data[f.CallStackField] = frames
+			}
+		case ErrorList:
+			// One stack trace per error, null for the errors without one.
+			stacks, found := make([][]string, len(err)), false
+			for i, e := range err {
+				stacks[i] = errorStackTrace(e)
+				found = found || stacks[i] != nil
+			}
+			if found {
+				data[f.CallStackField] = stacks
+			}
+		}
+	}
+
+	fields := data
 	if f.DataKey != "" {
 		newData := make(Fields, 4)
 		newData[f.DataKey] = data
@@ -90,10 +148,14 @@ func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
 		data[f.FieldMap.resolve(FieldKeyLogrusError)] = entry.err
 	}
 	if !f.DisableTimestamp {
-		data[f.FieldMap.resolve(FieldKeyTime)] = entry.Time.Format(timestampFormat)
+		data[f.FieldMap.resolve(FieldKeyTime)] = formatTimestamp(entry.Time, timestampFormat)
 	}
 	data[f.FieldMap.resolve(FieldKeyMsg)] = entry.Message
-	data[f.FieldMap.resolve(FieldKeyLevel)] = entry.Level.String()
+	if f.LevelEncoder != nil {
+		data[f.FieldMap.resolve(FieldKeyLevel)] = f.LevelEncoder(entry.Level)
+	} else {
+		data[f.FieldMap.resolve(FieldKeyLevel)] = entry.Level.String()
+	}
 	if entry.HasCaller() {
 		funcVal := entry.Caller.Function
 		fileVal := fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
@@ -115,14 +177,107 @@ func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
 		b = &bytes.Buffer{}
 	}
 
-	encoder := json.NewEncoder(b)
-	encoder.SetEscapeHTML(!f.DisableHTMLEscape)
+	if err := f.encode(b, data); err != nil {
+		if f.ErrorOnMarshalFailure || !replaceUnmarshalableFields(fields) {
+			return nil, fmt.Errorf("failed to marshal fields to JSON, %w", err)
+		}
+		if err := f.encode(b, data); err != nil {
+			return nil, fmt.Errorf("failed to marshal fields to JSON, %w", err)
+		}
+	}
+
+	if f.DisableNewline {
+		b.Truncate(b.Len() - 1)
+	}
+	return b.Bytes(), nil
+}
+
+// encode writes data to b followed by a newline, with the Marshaler when set.
+func (f *JSONFormatter) encode(b *bytes.Buffer, data Fields) error {
+	indent := ""
 	if f.PrettyPrint {
-		encoder.SetIndent("", "  ")
+		indent = f.Indent
+		if indent == "" {
+			indent = "  "
+		}
+	}
+
+	if f.Marshaler == nil {
+		encoder := json.NewEncoder(b)
+		encoder.SetEscapeHTML(!f.DisableHTMLEscape)
+		encoder.SetIndent("", indent)
+		return encoder.Encode(data)
+	}
+
+	serialized, err := f.Marshaler(data)
+	if err != nil {
+		return err
+	}
+	if indent != "" {
+		if err := json.Indent(b, serialized, "", indent); err != nil {
+			return err
+		}
+	} else {
+		b.Write(serialized)
 	}
-	if err := encoder.Encode(data); err != nil {
-		return nil, fmt.Errorf("failed to marshal fields to JSON, %w", err)
+	b.WriteByte('\n')
+	return nil
+}
+
+// replaceUnmarshalableFields replaces the values which can't be marshaled by
+// a placeholder, and reports whether any was found.
+func replaceUnmarshalableFields(fields Fields) bool {
+	replaced := false
+	for k, v := range fields {
+		if _, err := json.Marshal(v); err != nil {
+			fields[k] = fmt.Sprintf("<error: json marshal failed: %v>", err)
+			replaced = true
+		}
 	}
+	return replaced
+}
 
-	return b.Bytes(), nil
+// errorStackTrace returns the frames of the innermost stack trace found in the
+// chain of err. The StackTrace method is found by reflection so that any
+// slice of program counters is accepted, like errors.StackTrace of
+// github.com/pkg/errors, without depending on the package.
+func errorStackTrace(err error) []string {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if stack := stackTraceOf(err); stack != nil {
+			pcs = stack
+		}
+	}
+	if pcs == nil {
+		return nil
+	}
+
+	var stack []string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			return stack
+		}
+	}
+}
+
+func stackTraceOf(err error) []uintptr {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() {
+		return nil
+	}
+	typ := method.Type()
+	if typ.NumIn() != 0 || typ.NumOut() != 1 ||
+		typ.Out(0).Kind() != reflect.Slice || typ.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := method.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
 }
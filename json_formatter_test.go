@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestErrorNotLost(t *testing.T) {
@@ -47,6 +48,153 @@ func TestErrorNotLostOnFieldNotNamedError(t *testing.T) {
 	}
 }
 
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("boom")
+}
+
+func TestUnmarshalableFieldIsReplaced(t *testing.T) {
+	for _, formatter := range []*JSONFormatter{{}, {DataKey: "data"}} {
+		b, err := formatter.Format(WithFields(Fields{
+			"channel": make(chan int),
+			"custom":  failingMarshaler{},
+			"ok":      "fine",
+		}))
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+
+		entry := make(map[string]interface{})
+		err = json.Unmarshal(b, &entry)
+		if err != nil {
+			t.Fatal("Unable to unmarshal formatted entry: ", err)
+		}
+
+		fields := entry
+		if formatter.DataKey != "" {
+			fields = entry[formatter.DataKey].(map[string]interface{})
+		}
+		if fields["ok"] != "fine" {
+			t.Error("Marshalable field should be kept", entry)
+		}
+		for _, key := range []string{"channel", "custom"} {
+			if v, _ := fields[key].(string); !strings.HasPrefix(v, "<error: json marshal failed: ") {
+				t.Errorf("Field %q should be replaced by a placeholder, got %v", key, fields[key])
+			}
+		}
+		if _, ok := entry["level"]; !ok {
+			t.Error("Level should be written", entry)
+		}
+	}
+}
+
+func TestErrorOnMarshalFailure(t *testing.T) {
+	formatter := &JSONFormatter{ErrorOnMarshalFailure: true}
+
+	_, err := formatter.Format(WithField("channel", make(chan int)))
+	if err == nil {
+		t.Error("Expected a marshal error")
+	}
+}
+
+// stackError mimics the errors of github.com/pkg/errors, whose StackTrace
+// method returns a slice of program counters.
+type stackError struct {
+	msg   string
+	stack []uintptr
+}
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &stackError{msg: msg, stack: pcs[:n]}
+}
+
+func (e *stackError) Error() string         { return e.msg }
+func (e *stackError) StackTrace() []uintptr { return e.stack }
+
+func TestErrorCallStackField(t *testing.T) {
+	formatter := &JSONFormatter{CallStackField: "stack"}
+
+	wrapped := fmt.Errorf("context: %w", newStackError("wild walrus"))
+	b, err := formatter.Format(WithError(wrapped))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	if entry["error"] != "context: wild walrus" {
+		t.Error("Error field should still be the error message", entry["error"])
+	}
+	stack, ok := entry["stack"].([]interface{})
+	if !ok || len(stack) == 0 {
+		t.Fatal("Stack trace not set", entry["stack"])
+	}
+	if frame, _ := stack[0].(string); !strings.HasPrefix(frame, "github.com/sirupsen/logrus.TestErrorCallStackField ") ||
+		!strings.Contains(frame, "json_formatter_test.go:") {
+		t.Error("First frame should be the test function", frame)
+	}
+}
+
+func TestWithErrors(t *testing.T) {
+	formatter := &JSONFormatter{CallStackField: "stack"}
+
+	b, err := formatter.Format(WithErrors(errors.New("timeout"), newStackError("wild walrus")))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	errs, ok := entry["error"].([]interface{})
+	if !ok || len(errs) != 2 || errs[0] != "timeout" || errs[1] != "wild walrus" {
+		t.Fatal("Error field should be the array of the error messages", entry["error"])
+	}
+	stacks, ok := entry["stack"].([]interface{})
+	if !ok || len(stacks) != 2 {
+		t.Fatal("Stack traces not set", entry["stack"])
+	}
+	if stacks[0] != nil {
+		t.Error("The error without stack trace should have a null stack", stacks[0])
+	}
+	if stack, _ := stacks[1].([]interface{}); len(stack) == 0 {
+		t.Error("The stack trace of the second error should be set", stacks[1])
+	}
+}
+
+func TestErrorCallStackFieldNotSetByDefault(t *testing.T) {
+	for _, formatter := range []*JSONFormatter{{}, {CallStackField: "stack"}} {
+		var e error = newStackError("wild walrus")
+		if formatter.CallStackField != "" {
+			e = errors.New("no stack")
+		}
+		b, err := formatter.Format(WithError(e))
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+
+		entry := make(map[string]interface{})
+		err = json.Unmarshal(b, &entry)
+		if err != nil {
+			t.Fatal("Unable to unmarshal formatted entry: ", err)
+		}
+
+		if len(entry) != 4 {
+			t.Error("No stack trace field expected", entry)
+		}
+	}
+}
+
 func TestFieldClashWithTime(t *testing.T) {
 	formatter := &JSONFormatter{}
 
@@ -162,6 +310,48 @@ func TestFieldClashWithRemappedFields(t *testing.T) {
 	}
 }
 
+func TestFieldClashWithECSFieldMap(t *testing.T) {
+	formatter := &JSONFormatter{
+		FieldMap: FieldMap{
+			FieldKeyTime:  "@timestamp",
+			FieldKeyMsg:   "message",
+			FieldKeyLevel: "log.level",
+		},
+	}
+
+	logEntry := WithField("message", "user message")
+	logEntry.Message = "log message"
+	logEntry.Level = ErrorLevel
+	b, err := formatter.Format(logEntry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	if entry["message"] != "log message" {
+		t.Errorf("Expected message key to hold the entry message; got %v", entry["message"])
+	}
+	if entry["fields.message"] != "user message" {
+		t.Errorf("Expected user message field to be moved to fields.message; got %v", entry["fields.message"])
+	}
+	if entry["log.level"] != "error" {
+		t.Errorf("Expected log.level key to hold the entry level; got %v", entry["log.level"])
+	}
+	if _, ok := entry["@timestamp"]; !ok {
+		t.Error("Expected @timestamp key to be present")
+	}
+	for _, field := range []string{"msg", "level", "time"} {
+		if _, ok := entry[field]; ok {
+			t.Errorf("Expected default key %v to be remapped", field)
+		}
+	}
+}
+
 func TestFieldsInNestedDictionary(t *testing.T) {
 	formatter := &JSONFormatter{
 		DataKey: "args",
@@ -204,6 +394,46 @@ func TestFieldsInNestedDictionary(t *testing.T) {
 	}
 }
 
+func TestUserFieldsNestedUnderDataKey(t *testing.T) {
+	formatter := &JSONFormatter{
+		DataKey: "data",
+	}
+
+	logEntry := WithFields(Fields{
+		"key1": "value1",
+		"key2": "value2",
+	})
+	logEntry.Level = InfoLevel
+	logEntry.Message = "nested"
+
+	b, err := formatter.Format(logEntry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	data, ok := entry["data"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected user fields under 'data'", entry)
+	}
+	if data["key1"] != "value1" || data["key2"] != "value2" || len(data) != 2 {
+		t.Error("Expected data.key1 and data.key2 only", data)
+	}
+	if entry["msg"] != "nested" {
+		t.Error("Expected 'msg' at top level", entry)
+	}
+	for _, field := range []string{"key1", "key2"} {
+		if _, present := entry[field]; present {
+			t.Errorf("Expected field %v not to be present at top level", field)
+		}
+	}
+}
+
 func TestJSONEntryEndsWithNewline(t *testing.T) {
 	formatter := &JSONFormatter{}
 
@@ -251,6 +481,38 @@ func TestJSONLevelKey(t *testing.T) {
 	}
 }
 
+func TestJSONLevelEncoder(t *testing.T) {
+	gcpSeverities := map[Level]int{
+		PanicLevel: 800,
+		FatalLevel: 600,
+		ErrorLevel: 500,
+		WarnLevel:  400,
+		InfoLevel:  200,
+		DebugLevel: 100,
+		TraceLevel: 100,
+	}
+	formatter := &JSONFormatter{
+		FieldMap: FieldMap{
+			FieldKeyLevel: "severity",
+		},
+		LevelEncoder: func(level Level) interface{} {
+			return gcpSeverities[level]
+		},
+	}
+
+	b, err := formatter.Format(&Entry{Level: ErrorLevel, Message: "oops"})
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+	s := string(b)
+	if !strings.Contains(s, `"severity":500`) {
+		t.Error("Expected the numeric severity of the error level", s)
+	}
+	if strings.Contains(s, `"level"`) {
+		t.Error("Expected no level key", s)
+	}
+}
+
 func TestJSONTimeKey(t *testing.T) {
 	formatter := &JSONFormatter{
 		FieldMap: FieldMap{
@@ -370,3 +632,113 @@ func TestJSONEnableHTMLEscape(t *testing.T) {
 		t.Error("Message should be HTML escaped", s)
 	}
 }
+
+func TestJSONDisableHTMLEscapeKeepsURLs(t *testing.T) {
+	for _, formatter := range []*JSONFormatter{{DisableHTMLEscape: true}, {DisableHTMLEscape: true, PrettyPrint: true}} {
+		b, err := formatter.Format(&Entry{Message: "https://x/?a=1&b=2"})
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		s := string(b)
+		if !strings.Contains(s, `"https://x/?a=1&b=2"`) {
+			t.Error("Ampersand should not be escaped", s)
+		}
+		if !strings.HasSuffix(s, "}\n") {
+			t.Error("Entry should end with a newline", s)
+		}
+	}
+}
+
+func TestJSONUnixTimestampFormats(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 30, 45, 123456789, time.UTC)
+
+	for format, expected := range map[string]string{
+		TimestampFormatUnix:      `"time":1622550645}`,
+		TimestampFormatUnixMilli: `"time":1622550645123}`,
+	} {
+		formatter := &JSONFormatter{TimestampFormat: format}
+
+		b, err := formatter.Format(&Entry{Time: now, Message: "epoch"})
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		if s := string(b); !strings.Contains(s, expected) {
+			t.Errorf("Expected %s timestamp %s, got %s", format, expected, s)
+		}
+	}
+}
+
+func TestJSONPrettyPrintIndent(t *testing.T) {
+	entry := &Entry{Message: "indented", Data: Fields{}}
+
+	b, err := (&JSONFormatter{PrettyPrint: true, DisableTimestamp: true, Indent: "\t"}).Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+	expected := "{\n\t\"level\": \"panic\",\n\t\"msg\": \"indented\"\n}\n"
+	if s := string(b); s != expected {
+		t.Errorf("Expected tab indented output %q, got %q", expected, s)
+	}
+
+	b, err = (&JSONFormatter{PrettyPrint: true, DisableTimestamp: true}).Format(entry)
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+	expected = "{\n  \"level\": \"panic\",\n  \"msg\": \"indented\"\n}\n"
+	if s := string(b); s != expected {
+		t.Errorf("Expected two spaces indented output %q, got %q", expected, s)
+	}
+}
+
+func TestJSONCustomMarshaler(t *testing.T) {
+	calls := 0
+	marshaler := func(v interface{}) ([]byte, error) {
+		calls++
+		return json.Marshal(v)
+	}
+	entry := &Entry{
+		Time:    time.Date(2021, 6, 1, 12, 30, 45, 0, time.UTC),
+		Level:   WarnLevel,
+		Message: "<b>parity</b>",
+		Data:    Fields{"user": "alice", "count": 3, "error": errors.New("wild walrus")},
+	}
+
+	for _, prettyPrint := range []bool{false, true} {
+		expected, err := (&JSONFormatter{PrettyPrint: prettyPrint}).Format(entry)
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		b, err := (&JSONFormatter{PrettyPrint: prettyPrint, Marshaler: marshaler}).Format(entry)
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		if string(b) != string(expected) {
+			t.Errorf("Expected the custom marshaler output %q to be %q", b, expected)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected the custom marshaler to be called twice, got %d", calls)
+	}
+}
+
+func TestJSONDisableNewline(t *testing.T) {
+	for _, formatter := range []*JSONFormatter{{}, {PrettyPrint: true}, {Marshaler: json.Marshal}} {
+		entry := &Entry{Message: "framed", Data: Fields{}}
+		b, err := formatter.Format(entry)
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		if b[len(b)-1] != '\n' {
+			t.Errorf("Expected a trailing newline by default, got %q", b)
+		}
+
+		formatter.DisableNewline = true
+		b, err = formatter.Format(entry)
+		if err != nil {
+			t.Fatal("Unable to format entry: ", err)
+		}
+		if b[len(b)-1] != '}' {
+			t.Errorf("Expected no trailing newline, got %q", b)
+		}
+	}
+}
@@ -60,3 +60,25 @@ func TestLevelMarshalText(t *testing.T) {
 		})
 	}
 }
+
+func TestLevelConfigDecoding(t *testing.T) {
+	type config struct {
+		Level logrus.Level `json:"level"`
+	}
+
+	for text, level := range map[string]logrus.Level{
+		"warn":    logrus.WarnLevel,
+		"warning": logrus.WarnLevel,
+		"TRACE":   logrus.TraceLevel,
+	} {
+		var c config
+		require.NoError(t, json.Unmarshal([]byte(`{"level":"`+text+`"}`), &c))
+		require.Equal(t, level, c.Level)
+	}
+
+	var c config
+	require.Error(t, json.Unmarshal([]byte(`{"level":"loud"}`), &c))
+
+	_, err := logrus.Level(42).MarshalText()
+	require.Error(t, err)
+}
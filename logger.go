@@ -2,8 +2,17 @@ package logrus
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,8 +26,18 @@ type LogFunction func() []interface{}
 type Logger struct {
 	// The logs are `io.Copy`'d to this in a mutex. It's common to set this to a
 	// file, or leave it default which is `os.Stderr`. You can also set this to
-	// something more adventurous, such as logging to Kafka.
+	// something more adventurous, such as logging to Kafka. Use SetOutput to
+	// change it while other goroutines are logging, assigning it directly is
+	// not safe for concurrent use.
 	Out io.Writer
+	// OnWriteError, when set, is called with the errors returned by the output,
+	// e.g. to reopen a file after a failure. It is called without holding the
+	// logger lock, so it may call SetOutput. Write errors are also reported to
+	// stderr, at most once per second.
+	OnWriteError func(error)
+	// OnFieldError, when set, is called with the *FieldSchemaError of the
+	// fields dropped by FieldSchema, without holding the logger lock.
+	OnFieldError func(error)
 	// Hooks for the logger instance. These allow firing events based on logging
 	// levels and log entries. For example, to send errors to an error tracking
 	// service, log to StatsD or dump the core on fatal errors.
@@ -28,11 +47,66 @@ type Logger struct {
 	// TextFormatter is the default. In development (when a TTY is attached) it
 	// logs with colors, but to a file it wouldn't. You can easily implement your
 	// own that implements the `Formatter` interface, see the `README` or included
-	// formatters for examples.
+	// formatters for examples. Use SetFormatter to change it while other
+	// goroutines are logging, assigning it directly is not safe for concurrent
+	// use.
 	Formatter Formatter
 
-	// Flag for whether to log caller info (off by default)
+	// FieldSchema optionally enforces the reflect.Kind of field values by key.
+	// It is checked when an entry is logged, on all its fields, including the
+	// DefaultFields and the fields added by WithContext or directly to Data,
+	// but not on the fields added by hooks, nor on the entries of disabled
+	// levels or rendered by Entry.Render. A field whose value doesn't match is
+	// dropped, reported in the `logrus_error` field and passed to OnFieldError
+	// as a *FieldSchemaError. It is nil (disabled) by default.
+	// Use SetFieldSchema to change it while other goroutines are logging.
+	FieldSchema map[string]reflect.Kind
+
+	// DuplicateKeyPolicy decides what happens when a field is added to an entry
+	// already holding the key, e.g. `WithField("k", 1).WithField("k", 2)`. The
+	// last value wins by default.
+	DuplicateKeyPolicy DuplicateKeyPolicy
+
+	// InstanceID, when set, is added to every entry to tell apart the logs of
+	// instances sharing a hostname, e.g. several pods on one node. See
+	// NewInstanceID to generate one at startup. A field with the same key set
+	// on the entry takes precedence.
+	InstanceID string
+	// InstanceIDKey is the key of the InstanceID field, `instance_id` by
+	// default.
+	InstanceIDKey string
+
+	// DefaultFields are added to every entry when it is logged, e.g. the
+	// service name and version. The fields set on the entry take precedence.
+	// Use SetDefaultFields to change them while other goroutines are logging.
+	DefaultFields Fields
+
+	// OnEntry, when set, is called with every entry logged, once its fields
+	// are gathered and before hooks fire and it is formatted. It is a cheaper
+	// observation point than a hook registered for all levels, e.g. for
+	// metrics. The entry must not be modified nor retained.
+	OnEntry func(*Entry)
+	// ObserveDisabled makes OnEntry also observe the entries of the disabled
+	// levels, which are then built but not logged.
+	ObserveDisabled bool
+
+	// Flag for whether to log caller info (off by default). It is read once
+	// per entry, when it is logged, so the formatters and hooks see the value
+	// it had then. Use SetReportCaller to change it while other goroutines are
+	// logging, assigning it directly is not safe for concurrent use.
 	ReportCaller bool
+	// CallerSkipFrames is the number of extra frames to skip when reporting
+	// the caller, for libraries wrapping the logger in their own functions.
+	CallerSkipFrames int
+	// CallerSkipPackages lists package path prefixes whose frames are skipped
+	// when reporting the caller, like the frames of logrus itself.
+	CallerSkipPackages []string
+
+	// FlushOnLevel makes the logger flush its output, and the hooks of the
+	// entry level, after writing any entry at or above this severity. Only
+	// outputs and hooks implementing `Flush() error` (such as a `bufio.Writer`)
+	// are flushed. It defaults to PanicLevel.
+	FlushOnLevel Level
 
 	// The logging level the logger should log at. This is typically (and defaults
 	// to) `logrus.Info`, which allows Info(), Warn(), Error() and Fatal() to be
@@ -40,7 +114,23 @@ type Logger struct {
 	Level Level
 	// Used to sync writing to the log. Locking is enabled by Default
 	mu MutexWrap
-	// Reusable empty entry
+	// Whether the entries are discarded before being formatted, see NewDiscard
+	discard bool
+	// Outputs of the levels not written to Out, see SetLevelOutput
+	levelOutputs map[Level]io.Writer
+	// Levels of the caller packages, see SetPackageLevel, and the most verbose
+	// of them, accessed atomically
+	packageLevels   map[string]Level
+	maxPackageLevel uint32
+	// Last time a write error was reported to stderr, and the number of write
+	// errors not reported since, guarded by writeErrorMu which, unlike mu, is
+	// not disabled by SetNoLock
+	writeErrorMu          sync.Mutex
+	lastWriteErrorNotice  time.Time
+	suppressedWriteErrors int
+	// Reusable empty entries, borrowed by the logging methods of the logger to
+	// build the entry. The entries passed to hooks and formatters are copies,
+	// which are never reused.
 	entryPool sync.Pool
 	// Function to exit the application, defaults to `os.Exit()`
 	ExitFunc exitFunc
@@ -51,6 +141,11 @@ type Logger struct {
 
 type exitFunc func(int)
 
+// flusher is implemented by outputs and hooks which buffer their writes.
+type flusher interface {
+	Flush() error
+}
+
 type MutexWrap struct {
 	lock     sync.Mutex
 	disabled bool
@@ -95,6 +190,28 @@ func New() *Logger {
 	}
 }
 
+// NewDiscard creates a logger which discards all the entries, e.g. for
+// benchmarks or when logging is disabled. Its level is PanicLevel so that the
+// logging calls return before building the entries, and the entries of the
+// enabled levels are neither formatted nor passed to hooks or OnEntry, even
+// if the level is changed. Panic and Fatal still panic and exit.
+func NewDiscard() *Logger {
+	logger := New()
+	logger.Out = ioutil.Discard
+	logger.Level = PanicLevel
+	logger.discard = true
+	return logger
+}
+
+// NewInstanceID returns a random identifier suitable for Logger.InstanceID.
+func NewInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
 func (logger *Logger) newEntry() *Entry {
 	entry, ok := logger.entryPool.Get().(*Entry)
 	if ok {
@@ -103,8 +220,22 @@ func (logger *Logger) newEntry() *Entry {
 	return NewEntry(logger)
 }
 
+// releaseEntry resets an entry borrowed with newEntry and puts it back in the
+// pool.
 func (logger *Logger) releaseEntry(entry *Entry) {
-	entry.Data = map[string]interface{}{}
+	// The data of borrowed entries is copied, never shared, so keep the map.
+	for k := range entry.Data {
+		delete(entry.Data, k)
+	}
+	entry.Time = time.Time{}
+	entry.Level = 0
+	entry.Caller = nil
+	entry.Message = ""
+	entry.Buffer = nil
+	entry.Context = nil
+	entry.err = ""
+	entry.order = nil
+	entry.callerHidden = false
 	logger.entryPool.Put(entry)
 }
 
@@ -126,6 +257,14 @@ func (logger *Logger) WithFields(fields Fields) *Entry {
 	return entry.WithFields(fields)
 }
 
+// WithDefaults allocates a new entry and adds the fields to it. As the entry
+// has no fields yet, it is the same as WithFields.
+func (logger *Logger) WithDefaults(fields Fields) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithDefaults(fields)
+}
+
 // Add an error as single field to the log entry.  All it does is call
 // `WithError` for the given `error`.
 func (logger *Logger) WithError(err error) *Entry {
@@ -134,6 +273,14 @@ func (logger *Logger) WithError(err error) *Entry {
 	return entry.WithError(err)
 }
 
+// Add several errors as a single field to the log entry. All it does is call
+// `WithErrors` for the given errors.
+func (logger *Logger) WithErrors(errs ...error) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithErrors(errs...)
+}
+
 // Add a context to the log entry.
 func (logger *Logger) WithContext(ctx context.Context) *Entry {
 	entry := logger.newEntry()
@@ -149,7 +296,7 @@ func (logger *Logger) WithTime(t time.Time) *Entry {
 }
 
 func (logger *Logger) Logf(level Level, format string, args ...interface{}) {
-	if logger.IsLevelEnabled(level) {
+	if level == FatalLevel || logger.IsLevelEnabled(level) || logger.observesDisabled() {
 		entry := logger.newEntry()
 		entry.Logf(level, format, args...)
 		logger.releaseEntry(entry)
@@ -188,18 +335,25 @@ func (logger *Logger) Errorf(format string, args ...interface{}) {
 
 func (logger *Logger) Fatalf(format string, args ...interface{}) {
 	logger.Logf(FatalLevel, format, args...)
-	logger.Exit(1)
 }
 
 func (logger *Logger) Panicf(format string, args ...interface{}) {
 	logger.Logf(PanicLevel, format, args...)
 }
 
+// Render returns a message formatted as it would be written if logged at the
+// given level, without writing it. See Entry.Render.
+func (logger *Logger) Render(level Level, args ...interface{}) ([]byte, error) {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.Render(level, args...)
+}
+
 // Log will log a message at the level given as parameter.
-// Warning: using Log at Panic or Fatal level will not respectively Panic nor Exit.
-// For this behaviour Logger.Panic or Logger.Fatal should be used instead.
+// Like Logger.Panic and Logger.Fatal, using Log at Panic or Fatal level
+// respectively panics or exits, even when the Fatal level is disabled.
 func (logger *Logger) Log(level Level, args ...interface{}) {
-	if logger.IsLevelEnabled(level) {
+	if level == FatalLevel || logger.IsLevelEnabled(level) || logger.observesDisabled() {
 		entry := logger.newEntry()
 		entry.Log(level, args...)
 		logger.releaseEntry(entry)
@@ -207,10 +361,12 @@ func (logger *Logger) Log(level Level, args ...interface{}) {
 }
 
 func (logger *Logger) LogFn(level Level, fn LogFunction) {
-	if logger.IsLevelEnabled(level) {
+	if logger.IsLevelEnabled(level) || logger.observesDisabled() {
 		entry := logger.newEntry()
 		entry.Log(level, fn()...)
 		logger.releaseEntry(entry)
+	} else if level == FatalLevel {
+		logger.Exit(1)
 	}
 }
 
@@ -246,7 +402,6 @@ func (logger *Logger) Error(args ...interface{}) {
 
 func (logger *Logger) Fatal(args ...interface{}) {
 	logger.Log(FatalLevel, args...)
-	logger.Exit(1)
 }
 
 func (logger *Logger) Panic(args ...interface{}) {
@@ -285,15 +440,47 @@ func (logger *Logger) ErrorFn(fn LogFunction) {
 
 func (logger *Logger) FatalFn(fn LogFunction) {
 	logger.LogFn(FatalLevel, fn)
-	logger.Exit(1)
 }
 
 func (logger *Logger) PanicFn(fn LogFunction) {
 	logger.LogFn(PanicLevel, fn)
 }
 
+// LogContext logs a message at the level given as parameter, with the given
+// context attached to the entry for hooks (see WithContext).
+// Like Log, using it at Panic or Fatal level respectively panics or exits,
+// even when the Fatal level is disabled.
+func (logger *Logger) LogContext(ctx context.Context, level Level, args ...interface{}) {
+	if level == FatalLevel || logger.IsLevelEnabled(level) || logger.observesDisabled() {
+		entry := logger.newEntry()
+		entry.Context = ctx
+		entry.Log(level, args...)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) TraceContext(ctx context.Context, args ...interface{}) {
+	logger.LogContext(ctx, TraceLevel, args...)
+}
+
+func (logger *Logger) DebugContext(ctx context.Context, args ...interface{}) {
+	logger.LogContext(ctx, DebugLevel, args...)
+}
+
+func (logger *Logger) InfoContext(ctx context.Context, args ...interface{}) {
+	logger.LogContext(ctx, InfoLevel, args...)
+}
+
+func (logger *Logger) WarnContext(ctx context.Context, args ...interface{}) {
+	logger.LogContext(ctx, WarnLevel, args...)
+}
+
+func (logger *Logger) ErrorContext(ctx context.Context, args ...interface{}) {
+	logger.LogContext(ctx, ErrorLevel, args...)
+}
+
 func (logger *Logger) Logln(level Level, args ...interface{}) {
-	if logger.IsLevelEnabled(level) {
+	if level == FatalLevel || logger.IsLevelEnabled(level) || logger.observesDisabled() {
 		entry := logger.newEntry()
 		entry.Logln(level, args...)
 		logger.releaseEntry(entry)
@@ -332,14 +519,40 @@ func (logger *Logger) Errorln(args ...interface{}) {
 
 func (logger *Logger) Fatalln(args ...interface{}) {
 	logger.Logln(FatalLevel, args...)
-	logger.Exit(1)
 }
 
 func (logger *Logger) Panicln(args ...interface{}) {
 	logger.Logln(PanicLevel, args...)
 }
 
+// LogStartupBanner logs a single "process started" entry at Info level,
+// whose timestamp marks the start of the process. It records the process id,
+// hostname, Go version and version of the main module from the build
+// information, along with the given fields which take precedence. It is
+// intended to be called once at boot.
+func (logger *Logger) LogStartupBanner(fields Fields) {
+	banner := Fields{
+		"pid":        os.Getpid(),
+		"go_version": runtime.Version(),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		banner["hostname"] = hostname
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		banner["version"] = info.Main.Version
+	}
+	for k, v := range fields {
+		banner[k] = v
+	}
+	logger.WithFields(banner).Info("process started")
+}
+
+// Exit flushes the outputs and the hooks implementing `Flush() error`, such as
+// an AsyncWriter, so that the last entries are not lost, then runs the exit
+// handlers and calls ExitFunc. It is called by Fatal. When ExitHandlerTimeout
+// is set, it also bounds the flush, so a stalled output cannot block Exit.
 func (logger *Logger) Exit(code int) {
+	logger.flush()
 	runHandlers()
 	if logger.ExitFunc == nil {
 		logger.ExitFunc = os.Exit
@@ -347,6 +560,100 @@ func (logger *Logger) Exit(code int) {
 	logger.ExitFunc(code)
 }
 
+// writeFailed reports an output error to OnWriteError, and to stderr at most
+// once per second.
+func (logger *Logger) writeFailed(err error) {
+	logger.mu.Lock()
+	onWriteError := logger.OnWriteError
+	logger.mu.Unlock()
+
+	logger.writeErrorMu.Lock()
+	now := time.Now()
+	notice := now.Sub(logger.lastWriteErrorNotice) >= time.Second
+	suppressed := logger.suppressedWriteErrors
+	if notice {
+		logger.lastWriteErrorNotice = now
+		logger.suppressedWriteErrors = 0
+	} else {
+		logger.suppressedWriteErrors++
+	}
+	logger.writeErrorMu.Unlock()
+
+	if notice {
+		if suppressed > 0 {
+			fmt.Fprintf(os.Stderr, "Failed to write to log, %v (%d similar errors suppressed)\n", err, suppressed)
+		} else {
+			fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+		}
+	}
+	if onWriteError != nil {
+		onWriteError(err)
+	}
+}
+
+// flush flushes the outputs and then the hooks implementing `Flush() error`.
+func (logger *Logger) flush() {
+	logger.mu.Lock()
+	outputs := []io.Writer{logger.Out}
+	for _, out := range logger.levelOutputs {
+		outputs = append(outputs, out)
+	}
+	var hooks []Hook
+	for _, level := range AllLevels {
+		hooks = append(hooks, logger.Hooks[level]...)
+	}
+	logger.mu.Unlock()
+
+	if ExitHandlerTimeout <= 0 {
+		flushAll(outputs, hooks)
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		flushAll(outputs, hooks)
+	}()
+
+	timer := time.NewTimer(ExitHandlerTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		fmt.Fprintln(os.Stderr, "Error: Logrus flush timed out after", ExitHandlerTimeout)
+	}
+}
+
+// flushAll flushes the outputs and hooks implementing `Flush() error`. It must
+// be called without holding the logger lock.
+func flushAll(outputs []io.Writer, hooks []Hook) {
+	for _, out := range outputs {
+		if f, ok := out.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to flush log, %v\n", err)
+			}
+		}
+	}
+
+	flushed := make(map[Hook]bool, len(hooks))
+	for _, hook := range hooks {
+		f, ok := hook.(flusher)
+		if !ok {
+			continue
+		}
+		// Hooks are usually registered for several levels, flush them once.
+		if reflect.TypeOf(hook).Comparable() {
+			if flushed[hook] {
+				continue
+			}
+			flushed[hook] = true
+		}
+		if err := f.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to flush hook: %v\n", err)
+		}
+	}
+}
+
 //When file is opened with appending mode, it's safe to
 //write concurrently to a file (within 4k message on Linux).
 //In these cases user can choose to disable the lock.
@@ -375,9 +682,58 @@ func (logger *Logger) AddHook(hook Hook) {
 	logger.Hooks.Add(hook)
 }
 
-// IsLevelEnabled checks if the log level of the logger is greater than the level param
+// observesDisabled reports whether entries of disabled levels must still be
+// built for OnEntry.
+func (logger *Logger) observesDisabled() bool {
+	return logger.ObserveDisabled && logger.OnEntry != nil && !logger.discard
+}
+
+// IsLevelEnabled checks if the log level of the logger is greater than the level param.
+// When package levels are set, it checks if the level is enabled for any
+// package, the level of the caller package being checked when logging.
 func (logger *Logger) IsLevelEnabled(level Level) bool {
-	return logger.level() >= level
+	return logger.level() >= level || Level(atomic.LoadUint32(&logger.maxPackageLevel)) >= level
+}
+
+// SetPackageLevel sets the level of the entries logged from the packages
+// whose import path starts with pkg, in place of the logger level, e.g. to
+// silence a noisy library:
+//
+//	logger.SetLevel(logrus.DebugLevel)
+//	logger.SetPackageLevel("github.com/noisy/lib", logrus.WarnLevel)
+//
+// The longest matching prefix wins. The caller package is looked up for each
+// entry once a package level is set, as with ReportCaller, and is available
+// to hooks with Entry.CallerPackage.
+func (logger *Logger) SetPackageLevel(pkg string, level Level) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	packageLevels := make(map[string]Level, len(logger.packageLevels)+1)
+	maxLevel := level
+	for p, l := range logger.packageLevels {
+		packageLevels[p] = l
+		if l > maxLevel {
+			maxLevel = l
+		}
+	}
+	packageLevels[pkg] = level
+	logger.packageLevels = packageLevels
+	atomic.StoreUint32(&logger.maxPackageLevel, uint32(maxLevel))
+}
+
+// packageLevelEnabled checks if the level is enabled for the caller package,
+// the caller must hold the lock.
+func (logger *Logger) packageLevelEnabled(level Level, pkg string) bool {
+	if len(logger.packageLevels) == 0 {
+		return true
+	}
+	enabled, matched := logger.level() >= level, -1
+	for prefix, l := range logger.packageLevels {
+		if len(prefix) > matched && strings.HasPrefix(pkg, prefix) {
+			enabled, matched = l >= level, len(prefix)
+		}
+	}
+	return enabled
 }
 
 // SetFormatter sets the logger formatter.
@@ -387,6 +743,13 @@ func (logger *Logger) SetFormatter(formatter Formatter) {
 	logger.Formatter = formatter
 }
 
+// GetFormatter returns the logger formatter.
+func (logger *Logger) GetFormatter() Formatter {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	return logger.Formatter
+}
+
 // SetOutput sets the logger output.
 func (logger *Logger) SetOutput(output io.Writer) {
 	logger.mu.Lock()
@@ -394,12 +757,65 @@ func (logger *Logger) SetOutput(output io.Writer) {
 	logger.Out = output
 }
 
+// SetLevelOutput sets the output of the entries of the given level, which are
+// written to Out otherwise. Setting a nil writer restores Out. Entries are
+// routed once formatted, after the hooks fired. For example, to send warnings
+// and errors to stderr and the rest to stdout:
+//
+//	logger.SetOutput(os.Stdout)
+//	for _, level := range []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel} {
+//		logger.SetLevelOutput(level, os.Stderr)
+//	}
+func (logger *Logger) SetLevelOutput(level Level, output io.Writer) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if output == nil {
+		delete(logger.levelOutputs, level)
+		return
+	}
+	if logger.levelOutputs == nil {
+		logger.levelOutputs = make(map[Level]io.Writer)
+	}
+	logger.levelOutputs[level] = output
+}
+
+// output returns the writer of the given level, the caller must hold the lock.
+func (logger *Logger) output(level Level) io.Writer {
+	if out, ok := logger.levelOutputs[level]; ok {
+		return out
+	}
+	return logger.Out
+}
+
 func (logger *Logger) SetReportCaller(reportCaller bool) {
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.ReportCaller = reportCaller
 }
 
+// SetFieldSchema sets the kinds enforced for the values of the fields.
+func (logger *Logger) SetFieldSchema(schema map[string]reflect.Kind) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.FieldSchema = schema
+}
+
+// SetDefaultFields sets the fields added to every entry.
+func (logger *Logger) SetDefaultFields(fields Fields) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.DefaultFields = fields
+}
+
+// SetCallerSkip sets the number of extra frames, and the package path
+// prefixes, to skip when reporting the caller.
+func (logger *Logger) SetCallerSkip(frames int, packages ...string) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.CallerSkipFrames = frames
+	logger.CallerSkipPackages = packages
+}
+
 // ReplaceHooks replaces the logger hooks and returns the old ones
 func (logger *Logger) ReplaceHooks(hooks LevelHooks) LevelHooks {
 	logger.mu.Lock()
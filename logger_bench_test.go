@@ -1,6 +1,8 @@
 package logrus
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -75,3 +77,93 @@ func doLoggerBenchmarkWithFormatter(b *testing.B, f Formatter) {
 		}
 	})
 }
+
+type unpooledBuffers struct{}
+
+func (unpooledBuffers) Get() *bytes.Buffer { return new(bytes.Buffer) }
+func (unpooledBuffers) Put(*bytes.Buffer)  {}
+
+// BenchmarkLoggerBufferPool compares the allocations of the formatter output
+// with the default buffer pool and without pooling.
+func BenchmarkLoggerBufferPool(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		pool BufferPool
+	}{
+		{"pooled", nil},
+		{"unpooled", unpooledBuffers{}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			log := New()
+			log.Formatter = &JSONFormatter{}
+			log.Out = ioutil.Discard
+			log.BufferPool = bc.pool
+			entry := log.WithFields(smallFields)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				entry.Info("aaa")
+			}
+		})
+	}
+}
+
+// BenchmarkWithFieldOnLargeEntry compares the allocations of adding a single
+// field to a 20 fields entry with WithField and with a one field WithFields.
+func BenchmarkWithFieldOnLargeEntry(b *testing.B) {
+	fields := make(Fields, 20)
+	for i := 0; i < 20; i++ {
+		fields[fmt.Sprintf("key%d", i)] = i
+	}
+	entry := New().WithFields(fields)
+
+	b.Run("WithField", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = entry.WithField("request_id", i)
+		}
+	})
+	b.Run("WithFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = entry.WithFields(Fields{"request_id": i})
+		}
+	})
+}
+
+// BenchmarkLoggerEntryPool compares the allocations of logging without fields
+// through the logger, which borrows pooled entries, and through new entries.
+func BenchmarkLoggerEntryPool(b *testing.B) {
+	log := New()
+	log.Out = ioutil.Discard
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			log.Info("aaa")
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewEntry(log).Info("aaa")
+		}
+	})
+}
+
+func BenchmarkDiscardLoggerFilteredDebug(b *testing.B) {
+	logger := NewDiscard()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debug("filtered")
+	}
+}
+
+func BenchmarkDiscardLoggerFilteredDebugf(b *testing.B) {
+	logger := NewDiscard()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debugf("filtered %s", "value")
+	}
+}
@@ -1,10 +1,21 @@
 package logrus
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -95,3 +106,570 @@ func TestLogger_SetBufferPool(t *testing.T) {
 	assert.Equal(t, pool.get, 1, "Logger.SetBufferPool(): The BufferPool.Get() must be called")
 	assert.Len(t, pool.buffers, 1, "Logger.SetBufferPool(): The BufferPool.Put() must be called")
 }
+
+func TestLazyValue(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+
+	calls := 0
+	lazy := LazyValue(func() interface{} {
+		calls++
+		return "computed"
+	})
+	entry := l.WithField("state", lazy)
+
+	entry.Debug("disabled")
+	assert.Equal(t, 0, calls, "lazy values must not be computed for disabled levels")
+
+	var seen interface{}
+	l.OnEntry = func(e *Entry) { seen = e.Data["state"] }
+	entry.Info("enabled")
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "computed", seen, "OnEntry and hooks must see the computed value")
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "computed", data["state"])
+	assert.NotContains(t, data, FieldKeyLogrusError)
+	assert.IsType(t, LazyValue(nil), entry.Data["state"], "the logged entry must not be modified")
+}
+
+func TestLogContextAtFatalAndPanicLevels(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&TextFormatter{DisableColors: true, DisableTimestamp: true})
+	var contexts []interface{}
+	l.OnEntry = func(e *Entry) { contexts = append(contexts, e.Context.Value(ctxKey{})) }
+	exits := 0
+	l.ExitFunc = func(code int) {
+		assert.Equal(t, 1, code)
+		exits++
+	}
+
+	l.LogContext(ctx, FatalLevel, "fatal")
+	assert.Equal(t, 1, exits)
+	assert.Equal(t, "level=fatal msg=fatal\n", buf.String())
+
+	assert.Panics(t, func() { l.LogContext(ctx, PanicLevel, "panic") })
+	assert.Equal(t, []interface{}{"request-1", "request-1"}, contexts)
+
+	l.SetLevel(PanicLevel)
+	l.LogContext(ctx, FatalLevel, "disabled")
+	assert.Equal(t, 2, exits, "LogContext must exit at Fatal level even when it is disabled")
+}
+
+func TestLogAtFatalLevelExits(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	exits := 0
+	l.ExitFunc = func(code int) {
+		assert.Equal(t, 1, code)
+		exits++
+	}
+
+	for _, level := range []Level{InfoLevel, PanicLevel} {
+		l.SetLevel(level)
+		buf.Reset()
+		exits = 0
+
+		l.Log(FatalLevel, "log")
+		l.Logf(FatalLevel, "%s", "logf")
+		l.Logln(FatalLevel, "logln")
+		l.Fatal("fatal")
+		l.FatalFn(func() []interface{} { return []interface{}{"fatalfn"} })
+		l.WithField("k", "v").Log(FatalLevel, "entry log")
+		l.WithField("k", "v").Fatalf("%s", "entry fatalf")
+		assert.Equal(t, 7, exits, "each fatal call must exit exactly once at level %s", level)
+
+		if level == PanicLevel {
+			assert.Empty(t, buf.String(), "disabled fatal entries must not be written")
+		} else {
+			assert.Equal(t, 7, strings.Count(buf.String(), "level=fatal"))
+		}
+	}
+
+	assert.Panics(t, func() { l.Log(PanicLevel, "panic") })
+}
+
+type orderRecordingHook struct {
+	events *[]string
+}
+
+func (h orderRecordingHook) Levels() []Level {
+	return AllLevels
+}
+
+func (h orderRecordingHook) Fire(*Entry) error {
+	*h.events = append(*h.events, "fire")
+	return nil
+}
+
+func (h orderRecordingHook) Flush() error {
+	*h.events = append(*h.events, "flush")
+	return nil
+}
+
+func TestFatalFlushesBeforeExit(t *testing.T) {
+	var events []string
+	out := &bytes.Buffer{}
+	buffered := bufio.NewWriter(out)
+
+	l := New()
+	l.SetOutput(buffered)
+	l.AddHook(orderRecordingHook{events: &events})
+	l.ExitFunc = func(int) {
+		events = append(events, "exit")
+		assert.Contains(t, out.String(), "the end", "the output must be flushed before exiting")
+	}
+
+	l.Fatal("the end")
+	assert.Equal(t, []string{"fire", "flush", "exit"}, events)
+}
+
+type stalledWriter struct {
+	bytes.Buffer
+	stalled chan struct{}
+}
+
+func (w *stalledWriter) Flush() error {
+	<-w.stalled
+	return nil
+}
+
+func TestFatalDoesNotWaitForAStalledFlush(t *testing.T) {
+	defer func(timeout time.Duration) { ExitHandlerTimeout = timeout }(ExitHandlerTimeout)
+	ExitHandlerTimeout = 10 * time.Millisecond
+
+	out := &stalledWriter{stalled: make(chan struct{})}
+	defer close(out.stalled)
+
+	l := New()
+	l.SetOutput(out)
+	exited := make(chan int, 1)
+	l.ExitFunc = func(code int) { exited <- code }
+
+	go l.Fatal("the end")
+	select {
+	case code := <-exited:
+		assert.Equal(t, 1, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Fatal blocked on a flush that never returns")
+	}
+
+	// The flush must not hold the logger lock.
+	l.Info("still logging")
+	assert.Contains(t, out.String(), "still logging")
+}
+
+func TestEntryIsLevelEnabled(t *testing.T) {
+	l := New()
+	entry := l.WithField("k", "v")
+	assert.True(t, entry.IsLevelEnabled(InfoLevel))
+	assert.False(t, entry.IsLevelEnabled(DebugLevel))
+
+	l.SetLevel(DebugLevel)
+	assert.True(t, entry.IsLevelEnabled(DebugLevel))
+}
+
+func TestRenderMatchesLoggedOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	hook := &flushCountingHook{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{
+		// Render and Info are called from different lines.
+		CallerPrettyfier: func(f *runtime.Frame) (string, string) { return f.Function, "" },
+	})
+	l.SetReportCaller(true)
+	l.InstanceID = "abcd"
+	l.AddHook(hook)
+
+	now := time.Now()
+	entry := l.WithField("k", "v").WithTime(now)
+
+	rendered, err := entry.Render(InfoLevel, "hello")
+	require.NoError(t, err)
+	entry.Info("hello")
+	assert.Equal(t, buf.String(), string(rendered))
+	assert.Equal(t, 1, hook.fired, "Render must not fire the hooks")
+
+	buf.Reset()
+	rendered, err = l.Render(DebugLevel, "disabled")
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(rendered, &data))
+	assert.Equal(t, "debug", data["level"])
+	assert.Equal(t, "disabled", data["msg"])
+	assert.Equal(t, "abcd", data[FieldKeyInstanceID])
+	assert.Contains(t, data, FieldKeyFunc)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteErrorsAreRateLimited(t *testing.T) {
+	stderr, err := ioutil.TempFile("", "stderr")
+	require.NoError(t, err)
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+	defer func(saved *os.File) { os.Stderr = saved }(os.Stderr)
+	os.Stderr = stderr
+
+	var observed []error
+	l := New()
+	l.SetOutput(failingWriter{})
+	l.OnWriteError = func(err error) {
+		observed = append(observed, err)
+		// The callback must be able to reconfigure the logger.
+		l.SetLevel(InfoLevel)
+	}
+
+	for i := 0; i < 100; i++ {
+		l.Info("lost")
+	}
+	assert.Len(t, observed, 100)
+	assert.EqualError(t, observed[0], "disk full")
+
+	notices, err := ioutil.ReadFile(stderr.Name())
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(notices), "Failed to write to log, disk full"), "stderr must not be flooded")
+}
+
+func TestWriteErrorsWithoutLock(t *testing.T) {
+	stderr, err := ioutil.TempFile("", "stderr")
+	require.NoError(t, err)
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+	defer func(saved *os.File) { os.Stderr = saved }(os.Stderr)
+	os.Stderr = stderr
+
+	var observed int32
+	l := New()
+	l.SetOutput(failingWriter{})
+	l.OnWriteError = func(error) { atomic.AddInt32(&observed, 1) }
+	l.SetNoLock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info("lost")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1000), atomic.LoadInt32(&observed))
+
+	notices, err := ioutil.ReadFile(stderr.Name())
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(notices), "Failed to write to log, disk full"), "stderr must not be flooded")
+}
+
+type flushCountingHook struct {
+	fired   int
+	flushed int
+}
+
+func (h *flushCountingHook) Levels() []Level {
+	return AllLevels
+}
+
+func (h *flushCountingHook) Fire(*Entry) error {
+	h.fired++
+	return nil
+}
+
+func (h *flushCountingHook) Flush() error {
+	h.flushed++
+	return nil
+}
+
+func TestLoggerFlushOnLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	buffered := bufio.NewWriter(out)
+	hook := &flushCountingHook{}
+
+	l := New()
+	l.SetOutput(buffered)
+	l.AddHook(hook)
+	l.FlushOnLevel = ErrorLevel
+
+	l.Info("buffered")
+	l.Warn("buffered")
+	assert.Equal(t, 0, out.Len(), "entries below FlushOnLevel must stay buffered")
+	assert.Equal(t, 0, hook.flushed)
+
+	l.Error("flushed")
+	assert.Contains(t, out.String(), "buffered")
+	assert.Contains(t, out.String(), "flushed")
+	assert.Equal(t, 0, buffered.Buffered())
+	assert.Equal(t, 1, hook.flushed)
+}
+
+func TestLoggerSetLevelOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	l := New()
+	l.SetOutput(&stdout)
+	l.SetFormatter(&TextFormatter{DisableTimestamp: true, DisableColors: true})
+	l.SetLevelOutput(ErrorLevel, &stderr)
+
+	l.Info("to stdout")
+	l.Error("to stderr")
+	assert.Equal(t, "level=info msg=\"to stdout\"\n", stdout.String())
+	assert.Equal(t, "level=error msg=\"to stderr\"\n", stderr.String())
+
+	l.SetLevelOutput(ErrorLevel, nil)
+	l.Error("back to stdout")
+	assert.Contains(t, stdout.String(), "back to stdout")
+	assert.NotContains(t, stderr.String(), "back to stdout")
+}
+
+func TestFieldSchema(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+	l.SetFieldSchema(map[string]reflect.Kind{"status_code": reflect.Int})
+	var reported []error
+	l.OnFieldError = func(err error) { reported = append(reported, err) }
+	l.OnWriteError = func(err error) { t.Errorf("unexpected write error: %v", err) }
+
+	var data map[string]interface{}
+
+	l.WithField("status_code", 200).Info("ok")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, float64(200), data["status_code"])
+	assert.NotContains(t, data, FieldKeyLogrusError)
+
+	buf.Reset()
+	data = nil
+	l.WithField("status_code", "200").Info("drift")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.NotContains(t, data, "status_code")
+	assert.Equal(t, `field "status_code" must be of kind int, got string`, data[FieldKeyLogrusError])
+	if assert.Len(t, reported, 1) {
+		var schemaErr *FieldSchemaError
+		require.True(t, errors.As(reported[0], &schemaErr))
+		assert.Equal(t, FieldSchemaError{Key: "status_code", Want: reflect.Int, Got: reflect.String}, *schemaErr)
+	}
+}
+
+func TestFieldSchemaCheckedOnAllFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+	l.SetFieldSchema(map[string]reflect.Kind{"status_code": reflect.Int, "service": reflect.String})
+	var reported []string
+	l.OnFieldError = func(err error) { reported = append(reported, err.Error()) }
+
+	l.SetDefaultFields(Fields{"service": 1})
+	entry := l.WithField("user", "alice")
+	entry.Data["status_code"] = "500"
+	entry.Info("drift")
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.NotContains(t, data, "service")
+	assert.NotContains(t, data, "status_code")
+	assert.Equal(t, "alice", data["user"])
+	expected := []string{
+		`field "service" must be of kind string, got int`,
+		`field "status_code" must be of kind int, got string`,
+	}
+	assert.Equal(t, strings.Join(expected, ", "), data[FieldKeyLogrusError])
+	assert.Equal(t, expected, reported)
+}
+
+func TestFieldSchemaNotCheckedOnDisabledEntries(t *testing.T) {
+	l := New()
+	l.SetOutput(ioutil.Discard)
+	l.SetFieldSchema(map[string]reflect.Kind{"status_code": reflect.Int})
+	l.OnFieldError = func(err error) { t.Errorf("unexpected field error: %v", err) }
+	var observed []*Entry
+	l.OnEntry = func(entry *Entry) { observed = append(observed, entry) }
+	l.ObserveDisabled = true
+
+	entry := l.WithField("status_code", "200")
+	entry.Debug("disabled")
+	_, err := entry.Render(InfoLevel, "rendered")
+	require.NoError(t, err)
+
+	if assert.Len(t, observed, 1) {
+		assert.Equal(t, "200", observed[0].Data["status_code"])
+	}
+}
+
+func TestDuplicateKeyPolicy(t *testing.T) {
+	l := New()
+	assert.Equal(t, 2, l.WithField("k", 1).WithField("k", 2).Data["k"])
+
+	l.DuplicateKeyPolicy = DuplicateKeyKeepFirst
+	assert.Equal(t, 1, l.WithField("k", 1).WithField("k", 2).Data["k"])
+
+	l.DuplicateKeyPolicy = DuplicateKeyCollect
+	parent := l.WithField("k", 1).WithField("k", 2)
+	first := parent.WithField("k", 3)
+	second := parent.WithField("k", 4)
+	assert.Equal(t, CollectedValues{1, 2}, parent.Data["k"])
+	assert.Equal(t, CollectedValues{1, 2, 3}, first.Data["k"])
+	assert.Equal(t, CollectedValues{1, 2, 4}, second.Data["k"])
+
+	buf := &bytes.Buffer{}
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+	first.Info("collected")
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, []interface{}{float64(1), float64(2), float64(3)}, data["k"])
+}
+
+func TestInstanceID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+
+	var data map[string]interface{}
+	l.Info("no instance")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.NotContains(t, data, FieldKeyInstanceID)
+
+	l.InstanceID = NewInstanceID()
+	assert.Len(t, l.InstanceID, 16)
+	assert.NotEqual(t, l.InstanceID, NewInstanceID())
+
+	buf.Reset()
+	data = nil
+	l.Info("with instance")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, l.InstanceID, data[FieldKeyInstanceID])
+
+	l.InstanceIDKey = "pod"
+	buf.Reset()
+	data = nil
+	l.WithField("pod", "explicit").Info("with instance")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "explicit", data["pod"])
+	assert.NotContains(t, data, FieldKeyInstanceID)
+}
+
+func TestOnEntry(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var observed []string
+
+	l := New()
+	l.SetOutput(buf)
+	l.OnEntry = func(e *Entry) {
+		observed = append(observed, e.Level.String()+":"+e.Message+":"+fmt.Sprint(e.Data["k"]))
+	}
+
+	l.WithField("k", "v").Info("info")
+	l.Debugf("%s", "debug")
+	assert.Equal(t, []string{"info:info:v"}, observed)
+
+	observed = nil
+	l.ObserveDisabled = true
+	l.WithField("k", "v").Debug("debug")
+	l.Tracef("%s", "trace")
+	l.Debugln("debugln")
+	assert.Equal(t, []string{"debug:debug:v", "trace:trace:<nil>", "debug:debugln:<nil>"}, observed)
+	assert.NotContains(t, buf.String(), "debug")
+}
+
+func TestOnEntryObservesDisabledEntriesAsLoggedOnes(t *testing.T) {
+	var observed []*Entry
+	l := New()
+	l.SetOutput(ioutil.Discard)
+	l.SetDefaultFields(Fields{"service": "api"})
+	l.InstanceID = "pod-1"
+	l.ReportCaller = true
+	l.ObserveDisabled = true
+	l.OnEntry = func(e *Entry) { observed = append(observed, e) }
+
+	l.WithField("lazy", LazyValue(func() interface{} { return 42 })).Debug("disabled")
+	l.WithField("lazy", LazyValue(func() interface{} { return 42 })).Info("enabled")
+
+	require.Len(t, observed, 2)
+	for _, e := range observed {
+		assert.Equal(t, "api", e.Data["service"], e.Message)
+		assert.Equal(t, "pod-1", e.Data[FieldKeyInstanceID], e.Message)
+		assert.Equal(t, 42, e.Data["lazy"], e.Message)
+		assert.NotNil(t, e.Caller, e.Message)
+	}
+}
+
+func TestLogStartupBanner(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+
+	l.LogStartupBanner(Fields{"service": "api", "version": "1.2.3"})
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "process started", data["msg"])
+	assert.Equal(t, "info", data["level"])
+	assert.Equal(t, float64(os.Getpid()), data["pid"])
+	assert.Equal(t, runtime.Version(), data["go_version"])
+	assert.Equal(t, "api", data["service"])
+	assert.Equal(t, "1.2.3", data["version"])
+	assert.Contains(t, data, "hostname")
+}
+
+func TestLoggerDefaultFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&TextFormatter{DisableTimestamp: true, DisableColors: true})
+	l.SetDefaultFields(Fields{"service": "api", "version": "1.2.3"})
+
+	l.Info("plain")
+	assert.Equal(t, "level=info msg=plain service=api version=1.2.3\n", buf.String())
+
+	buf.Reset()
+	l.WithField("service", "worker").Info("overridden")
+	assert.Equal(t, "level=info msg=overridden service=worker version=1.2.3\n", buf.String())
+}
+
+func TestNewDiscard(t *testing.T) {
+	logger := NewDiscard()
+	fired := 0
+	logger.Hooks.AddForAll(func(*Entry) error {
+		fired++
+		return nil
+	})
+	logger.OnEntry = func(*Entry) { fired++ }
+	logger.ObserveDisabled = true
+	exited := false
+	logger.ExitFunc = func(int) { exited = true }
+
+	logger.SetLevel(TraceLevel)
+	logger.WithField("k", "v").Info("discarded")
+	logger.Error("discarded")
+	assert.Equal(t, 0, fired, "hooks and OnEntry must not be called")
+
+	assert.PanicsWithValue(t, "discarded", func() {
+		defer func() { panic(recover().(*Entry).Message) }()
+		logger.Panic("discarded")
+	})
+	logger.Fatal("discarded")
+	assert.True(t, exited)
+	assert.Equal(t, 0, fired)
+}
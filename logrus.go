@@ -1,14 +1,76 @@
 package logrus
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"reflect"
 	"strings"
+	"time"
 )
 
 // Fields type, used to pass to `WithFields`.
 type Fields map[string]interface{}
 
+// LazyValue is a field value computed only if the entry is logged, for values
+// costly to compute on levels usually disabled:
+//
+//	log.WithField("state", logrus.LazyValue(func() interface{} {
+//		return dump(state)
+//	})).Debug("tick")
+//
+// The function is called once, when the entry is logged, before OnEntry and
+// the hooks, which all see the computed value. Entries formatted directly by
+// the included formatters compute it at format time. The computed value is
+// checked against the logger FieldSchema.
+type LazyValue func() interface{}
+
+// FieldSchemaError reports a field dropped because the kind of its value
+// doesn't match the logger FieldSchema.
+type FieldSchemaError struct {
+	Key  string
+	Want reflect.Kind
+	Got  reflect.Kind
+}
+
+func (e *FieldSchemaError) Error() string {
+	return fmt.Sprintf("field %q must be of kind %s, got %s", e.Key, e.Want, e.Got)
+}
+
+// DuplicateKeyPolicy defines what happens when a field is added to an entry
+// which already has a field with the same key.
+type DuplicateKeyPolicy uint8
+
+const (
+	// DuplicateKeyOverwrite keeps the last value added. This is the default.
+	DuplicateKeyOverwrite DuplicateKeyPolicy = iota
+	// DuplicateKeyKeepFirst keeps the first value added and ignores the others.
+	DuplicateKeyKeepFirst
+	// DuplicateKeyCollect keeps all the values added, in order, as
+	// CollectedValues.
+	DuplicateKeyCollect
+)
+
+// CollectedValues holds the values of a key added several times to an entry
+// when the logger uses DuplicateKeyCollect. The JSONFormatter renders it as an
+// array and the TextFormatter as `key="[v1 v2]"`.
+type CollectedValues []interface{}
+
+// ErrorList holds the errors added to an entry with WithErrors. The
+// JSONFormatter renders it as an array of the error messages and the
+// TextFormatter as `error="[err1 err2]"`.
+type ErrorList []error
+
+// MarshalJSON encodes the errors as an array of their messages.
+func (errs ErrorList) MarshalJSON() ([]byte, error) {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = fmt.Sprint(err)
+	}
+	return json.Marshal(messages)
+}
+
 // Level type
 type Level uint32
 
@@ -21,14 +83,29 @@ func (level Level) String() string {
 	}
 }
 
+// UnknownLevelError is the error returned by ParseLevel for strings which
+// are not a level name.
+type UnknownLevelError struct {
+	// Level is the string which failed to parse.
+	Level string
+}
+
+func (e *UnknownLevelError) Error() string {
+	return fmt.Sprintf("not a valid logrus Level: %q", e.Level)
+}
+
 // ParseLevel takes a string level and returns the Logrus log level constant.
+// The case and surrounding whitespace are ignored. Unknown levels are reported
+// with an *UnknownLevelError. The syslog severities above error, "emergency",
+// "alert" and "critical", are parsed as ErrorLevel, the level their entries
+// are logged at, see ParseSeverity to keep the severity.
 func ParseLevel(lvl string) (Level, error) {
-	switch strings.ToLower(lvl) {
+	switch strings.ToLower(strings.TrimSpace(lvl)) {
 	case "panic":
 		return PanicLevel, nil
 	case "fatal":
 		return FatalLevel, nil
-	case "error":
+	case "error", "emergency", "alert", "critical":
 		return ErrorLevel, nil
 	case "warn", "warning":
 		return WarnLevel, nil
@@ -41,10 +118,11 @@ func ParseLevel(lvl string) (Level, error) {
 	}
 
 	var l Level
-	return l, fmt.Errorf("not a valid logrus Level: %q", lvl)
+	return l, &UnknownLevelError{Level: lvl}
 }
 
-// UnmarshalText implements encoding.TextUnmarshaler.
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the names
+// ParseLevel accepts, such as "warn" and "warning".
 func (level *Level) UnmarshalText(text []byte) error {
 	l, err := ParseLevel(string(text))
 	if err != nil {
@@ -56,6 +134,8 @@ func (level *Level) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// MarshalText implements encoding.TextMarshaler, so that levels are written
+// by name in JSON and other text based configuration formats.
 func (level Level) MarshalText() ([]byte, error) {
 	switch level {
 	case TraceLevel:
@@ -116,6 +196,9 @@ var (
 	_ StdLogger = &log.Logger{}
 	_ StdLogger = &Entry{}
 	_ StdLogger = &Logger{}
+
+	_ Ext2FieldLogger = &Entry{}
+	_ Ext2FieldLogger = &Logger{}
 )
 
 // StdLogger is what your logrus-enabled library should take, that way
@@ -184,3 +267,18 @@ type Ext1FieldLogger interface {
 	Trace(args ...interface{})
 	Traceln(args ...interface{})
 }
+
+// Ext2FieldLogger extends Ext1FieldLogger with the context, time and level
+// methods, for passing to functions which only add fields and log without
+// exposing the logger configuration. Both Logger and Entry implement it.
+type Ext2FieldLogger interface {
+	Ext1FieldLogger
+	WithContext(ctx context.Context) *Entry
+	WithTime(t time.Time) *Entry
+	WithDefaults(fields Fields) *Entry
+
+	IsLevelEnabled(level Level) bool
+	Log(level Level, args ...interface{})
+	Logf(level Level, format string, args ...interface{})
+	Logln(level Level, args ...interface{})
+}
@@ -2,12 +2,16 @@ package logrus_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -16,6 +20,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	. "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/sirupsen/logrus/hooks/writer"
 	. "github.com/sirupsen/logrus/internal/testutils"
 )
 
@@ -64,6 +70,60 @@ func TestReportCallerWhenConfigured(t *testing.T) {
 		assert.Equal(t, "somekindoffunc", fields[FieldKeyFunc])
 		assert.Equal(t, "thisisafilename", fields[FieldKeyFile])
 	})
+
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.ReportCaller = true
+		log.CallerSkipFrames = 1
+		wrappedPrint(log, "testWithCallerSkipFrames")
+	}, func(fields Fields) {
+		assert.Equal(t,
+			"github.com/sirupsen/logrus_test.TestReportCallerWhenConfigured.func9", fields[FieldKeyFunc])
+	})
+
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.SetReportCaller(true)
+		log.SetCallerSkip(0, "github.com/sirupsen/logrus_test")
+		wrappedPrint(log, "testWithCallerSkipPackages")
+	}, func(fields Fields) {
+		assert.Equal(t,
+			"github.com/sirupsen/logrus/internal/testutils.LogAndAssertJSON", fields[FieldKeyFunc])
+	})
+}
+
+func TestCallerPrettyfierWithStructuredKeys(t *testing.T) {
+	prettyfier := func(f *runtime.Frame) (string, string) {
+		function := f.Function[strings.LastIndex(f.Function, "/")+1:]
+		function = function[strings.Index(function, ".")+1:]
+		return function, fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+	}
+	fieldMap := FieldMap{
+		FieldKeyFunc: "caller.function",
+		FieldKeyFile: "caller.file",
+	}
+
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.ReportCaller = true
+		log.Formatter = &JSONFormatter{CallerPrettyfier: prettyfier, FieldMap: fieldMap}
+		log.Print("testWithStructuredCaller")
+	}, func(fields Fields) {
+		assert.Equal(t, "TestCallerPrettyfierWithStructuredKeys.func2", fields["caller.function"])
+		assert.Regexp(t, `^logrus_test\.go:\d+$`, fields["caller.file"])
+		assert.NotContains(t, fields, FieldKeyFunc)
+	})
+
+	LogAndAssertText(t, func(log *Logger) {
+		log.ReportCaller = true
+		log.Formatter = &TextFormatter{DisableColors: true, CallerPrettyfier: prettyfier, FieldMap: fieldMap}
+		log.Print("testWithStructuredCaller")
+	}, func(fields map[string]string) {
+		assert.Equal(t, "TestCallerPrettyfierWithStructuredKeys.func4", fields["caller.function"])
+		assert.Regexp(t, `^logrus_test\.go:\d+$`, fields["caller.file"])
+	})
+}
+
+// wrappedPrint stands for the logging function of a wrapper library.
+func wrappedPrint(log *Logger, msg string) {
+	log.Print(msg)
 }
 
 func logSomething(t *testing.T, message string) Fields {
@@ -124,6 +184,33 @@ func TestInfo(t *testing.T) {
 	})
 }
 
+func TestTrace(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.SetLevel(TraceLevel)
+		log.Trace("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "test", fields["msg"])
+		assert.Equal(t, "trace", fields["level"])
+	})
+}
+
+func TestTraceIsFilteredAtInfoLevel(t *testing.T) {
+	var buffer bytes.Buffer
+	hook := new(TestHook)
+
+	logger := New()
+	logger.Out = &buffer
+	logger.AddHook(hook)
+
+	logger.Trace("test")
+	logger.Tracef("%s", "test")
+	logger.Traceln("test")
+	logger.WithField("k", "v").Trace("test")
+
+	assert.Empty(t, buffer.String())
+	assert.False(t, hook.Fired)
+}
+
 func TestWarn(t *testing.T) {
 	LogAndAssertJSON(t, func(log *Logger) {
 		log.Warn("test")
@@ -174,6 +261,34 @@ func TestInfoShouldAddSpacesBetweenTwoNonStrings(t *testing.T) {
 	})
 }
 
+func TestInfolnSingleArgumentHasNoTrailingSpace(t *testing.T) {
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.Infoln("test")
+	}, func(fields Fields) {
+		assert.Equal(t, "test", fields["msg"])
+	})
+	LogAndAssertJSON(t, func(log *Logger) {
+		log.WithField("k", "v").Warnln(10)
+	}, func(fields Fields) {
+		assert.Equal(t, "10", fields["msg"])
+	})
+}
+
+func TestLnFunctionsHaveNoTrailingWhitespaceInText(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = &TextFormatter{DisableTimestamp: true, DisableColors: true}
+
+	logger.Infoln("test")
+	logger.Println("mixed", 10, errors.New("wild walrus"), true, 1.5)
+	logger.WithField("k", "v").Errorln("entry")
+
+	assert.Equal(t, "level=info msg=test\n"+
+		"level=info msg=\"mixed 10 wild walrus true 1.5\"\n"+
+		"level=error msg=entry k=v\n", buffer.String())
+}
+
 func TestInfoShouldNotAddSpacesBetweenStringAndNonstring(t *testing.T) {
 	LogAndAssertJSON(t, func(log *Logger) {
 		log.Info("test", 10)
@@ -268,6 +383,26 @@ func TestDefaultFieldsAreNotPrefixed(t *testing.T) {
 	})
 }
 
+func TestTextValuesRoundTripAsSingleTokens(t *testing.T) {
+	LogAndAssertText(t, func(log *Logger) {
+		log.Formatter.(*TextFormatter).QuoteEmptyFields = true
+		log.WithFields(Fields{
+			"expr":  "k=a b=c",
+			"quote": `say "hi"`,
+			"ctrl":  "a\tb\nc",
+			"empty": "",
+		}).Info("logfmt")
+	}, func(fields map[string]string) {
+		assert.Equal(t, "k=a b=c", fields["expr"])
+		assert.Equal(t, `say "hi"`, fields["quote"])
+		assert.Equal(t, "a\tb\nc", fields["ctrl"])
+		assert.Contains(t, fields, "empty")
+		assert.Equal(t, "", fields["empty"])
+		assert.NotContains(t, fields, "b")
+		assert.Len(t, fields, 7)
+	})
+}
+
 func TestWithTimeShouldOverrideTime(t *testing.T) {
 	now := time.Now().Add(24 * time.Hour)
 
@@ -414,7 +549,7 @@ func TestNestedLoggingReportsCorrectCaller(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, filepath.ToSlash(fmt.Sprintf("%s/logrus_test.go:%d", cwd, line-1)), filepath.ToSlash(fields["file"].(string)))
 
-	logger.ReportCaller = false // return to default value
+	logger.SetReportCaller(false) // return to default value
 }
 
 func logLoop(iterations int, reportCaller bool) {
@@ -453,12 +588,14 @@ func TestCallerReportingOverhead(t *testing.T) {
 
 // benchmarks for both with and without caller-function reporting
 func BenchmarkWithoutCallerTracing(b *testing.B) {
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		logLoop(1000, false)
 	}
 }
 
 func BenchmarkWithCallerTracing(b *testing.B) {
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		logLoop(1000, true)
 	}
@@ -541,6 +678,24 @@ func TestParseLevel(t *testing.T) {
 
 	_, err = ParseLevel("invalid")
 	assert.Equal(t, "not a valid logrus Level: \"invalid\"", err.Error())
+
+	l, err = ParseLevel(" Trace\n")
+	assert.Nil(t, err)
+	assert.Equal(t, TraceLevel, l)
+
+	for _, severity := range []string{"emergency", "ALERT", "critical"} {
+		l, err = ParseLevel(severity)
+		assert.Nil(t, err)
+		assert.Equal(t, ErrorLevel, l, "the severities above error are logged at the error level")
+	}
+}
+
+func TestParseLevelUnknownLevelError(t *testing.T) {
+	_, err := ParseLevel("verbose")
+
+	var unknown *UnknownLevelError
+	require.True(t, errors.As(fmt.Errorf("config: %w", err), &unknown))
+	assert.Equal(t, "verbose", unknown.Level)
 }
 
 func TestLevelString(t *testing.T) {
@@ -582,6 +737,31 @@ func TestLoggingRace(t *testing.T) {
 	wg.Wait()
 }
 
+func TestSetFormatterAndOutputRace(t *testing.T) {
+	logger := New()
+	logger.AddHook(&HookCallFunc{F: func() {}})
+	logger.AddHook(&writer.Hook{Writer: ioutil.Discard, LogLevels: AllLevels})
+
+	var wg sync.WaitGroup
+	wg.Add(300)
+	for i := 0; i < 100; i++ {
+		go func() {
+			logger.WithField("k", "v").Info("info")
+			wg.Done()
+		}()
+		go func() {
+			logger.SetFormatter(&JSONFormatter{})
+			_ = logger.GetFormatter()
+			wg.Done()
+		}()
+		go func() {
+			logger.SetOutput(ioutil.Discard)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
 func TestLoggingRaceWithHooksOnEntry(t *testing.T) {
 	logger := New()
 	hook := new(ModifyHook)
@@ -672,6 +852,43 @@ func TestLogrusInterfaces(t *testing.T) {
 	// test Entry
 	e := logger.WithField("another", "value")
 	fn(e)
+
+	fn2 := func(xl Ext2FieldLogger) {
+		if xl.IsLevelEnabled(InfoLevel) {
+			xl.WithContext(context.Background()).WithTime(time.Now()).Logf(InfoLevel, "Test %d", 2)
+		}
+	}
+	fn2(logger)
+	fn2(e)
+	assert.Equal(t, 2, strings.Count(buffer.String(), "Test 2"))
+}
+
+func TestPackageLevelFunctionsMatchLogger(t *testing.T) {
+	logger := New()
+	for name, pair := range map[string][2]interface{}{
+		"IsLevelEnabled": {IsLevelEnabled, logger.IsLevelEnabled},
+		"WithContext":    {WithContext, logger.WithContext},
+		"WithDefaults":   {WithDefaults, logger.WithDefaults},
+		"WithTime":       {WithTime, logger.WithTime},
+		"Log":            {Log, logger.Log},
+		"Logf":           {Logf, logger.Logf},
+		"Logln":          {Logln, logger.Logln},
+		"LogFn":          {LogFn, logger.LogFn},
+		"LogContext":     {LogContext, logger.LogContext},
+		"TraceContext":   {TraceContext, logger.TraceContext},
+		"DebugContext":   {DebugContext, logger.DebugContext},
+		"InfoContext":    {InfoContext, logger.InfoContext},
+		"WarnContext":    {WarnContext, logger.WarnContext},
+		"ErrorContext":   {ErrorContext, logger.ErrorContext},
+		"WithErrors":     {WithErrors, logger.WithErrors},
+		"WithSeverity":   {WithSeverity, logger.WithSeverity},
+		"LogSeverity":    {LogSeverity, logger.LogSeverity},
+		"Emergency":      {Emergency, logger.Emergency},
+		"Alert":          {Alert, logger.Alert},
+		"Critical":       {Critical, logger.Critical},
+	} {
+		assert.Equal(t, reflect.TypeOf(pair[1]), reflect.TypeOf(pair[0]), "%s must have the signature of the Logger method", name)
+	}
 }
 
 // Implements io.Writer using channels for synchronization, so we can wait on
@@ -796,3 +1013,67 @@ func TestSetReportCallerRace(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestToggleReportCallerWhileLogging(t *testing.T) {
+	l := New()
+	l.Out = ioutil.Discard
+	// Hooks format the entries outside of the logger lock.
+	l.Hooks.AddForAll(func(entry *Entry) error {
+		_, err := entry.String()
+		return err
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			l.SetReportCaller(i%2 == 0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			l.WithField("i", i).Info("toggling")
+		}
+	}()
+	wg.Wait()
+}
+
+func TestSetPackageLevel(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New()
+	logger.Out = &buffer
+	logger.Formatter = &TextFormatter{DisableTimestamp: true, DisableColors: true}
+	logger.SetLevel(DebugLevel)
+	logger.SetPackageLevel("github.com/other/lib", WarnLevel)
+
+	logger.Debug("other packages stay at debug")
+	assert.Equal(t, "level=debug msg=\"other packages stay at debug\"\n", buffer.String())
+
+	buffer.Reset()
+	logger.SetPackageLevel("github.com/sirupsen/logrus_test", WarnLevel)
+	logger.Debug("silenced")
+	logger.WithField("k", "v").Infof("%s", "silenced")
+	logger.Warn("kept")
+	assert.Equal(t, "level=warning msg=kept\n", buffer.String())
+
+	// The longest prefix wins, and may be more verbose than the logger.
+	buffer.Reset()
+	logger.SetLevel(InfoLevel)
+	logger.SetPackageLevel("github.com/sirupsen", ErrorLevel)
+	logger.SetPackageLevel("github.com/sirupsen/logrus_test", TraceLevel)
+	assert.True(t, logger.IsLevelEnabled(TraceLevel))
+	logger.Trace("enabled for this package")
+	assert.Equal(t, "level=trace msg=\"enabled for this package\"\n", buffer.String())
+}
+
+func TestEntryCallerPackage(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.Info("no caller")
+	assert.Equal(t, "", hook.LastEntry().CallerPackage())
+
+	logger.SetPackageLevel("github.com/other/lib", WarnLevel)
+	logger.Info("caller looked up for the package levels")
+	assert.Equal(t, "github.com/sirupsen/logrus_test", hook.LastEntry().CallerPackage())
+}
@@ -0,0 +1,54 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ProtoFormatter formats logs into length-delimited protobuf records: each
+// entry is written as its size, encoded as a varint, followed by the message
+// itself. This is the framing expected when streaming records, e.g. over gRPC.
+//
+// To keep protobuf out of the logrus dependencies, mapping the entry to a
+// message and marshaling it is left to the Marshal function:
+//
+//	formatter := &logrus.ProtoFormatter{
+//		Marshal: func(entry *logrus.Entry) ([]byte, error) {
+//			return proto.Marshal(&pb.LogRecord{
+//				Level:   entry.Level.String(),
+//				Message: entry.Message,
+//			})
+//		},
+//	}
+type ProtoFormatter struct {
+	// Marshal maps the entry to a protobuf message and returns its wire
+	// encoding.
+	Marshal func(*Entry) ([]byte, error)
+}
+
+// Format renders a single log entry
+func (f *ProtoFormatter) Format(entry *Entry) ([]byte, error) {
+	if f.Marshal == nil {
+		return nil, errors.New("ProtoFormatter requires a Marshal function")
+	}
+	msg, err := f.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entry to protobuf, %w", err)
+	}
+
+	var b *bytes.Buffer
+	if entry.Buffer != nil {
+		b = entry.Buffer
+	} else {
+		b = &bytes.Buffer{}
+	}
+
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(msg)))
+	b.Write(size[:n])
+	b.Write(msg)
+
+	return b.Bytes(), nil
+}
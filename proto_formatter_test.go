@@ -0,0 +1,50 @@
+package logrus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtoFormatterFramesRecords(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&ProtoFormatter{
+		Marshal: func(entry *Entry) ([]byte, error) {
+			return []byte(entry.Level.String() + ":" + entry.Message), nil
+		},
+	})
+
+	l.Info("first")
+	l.Warn("a somewhat longer second message")
+
+	r := bufio.NewReader(buf)
+	for _, expected := range []string{"info:first", "warning:a somewhat longer second message"} {
+		size, err := binary.ReadUvarint(r)
+		require.NoError(t, err)
+		record := make([]byte, size)
+		_, err = io.ReadFull(r, record)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(record))
+	}
+	_, err := r.ReadByte()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestProtoFormatterErrors(t *testing.T) {
+	_, err := (&ProtoFormatter{}).Format(NewEntry(New()))
+	assert.Error(t, err)
+
+	boom := errors.New("boom")
+	_, err = (&ProtoFormatter{
+		Marshal: func(*Entry) ([]byte, error) { return nil, boom },
+	}).Format(NewEntry(New()))
+	assert.True(t, errors.Is(err, boom))
+}
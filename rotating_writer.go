@@ -0,0 +1,107 @@
+package logrus
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// NewRotatingFileWriter returns a writer appending to the file at path,
+// typically to be used as `Logger.Out`, which rotates the file before it
+// exceeds maxBytes. The rotated files are renamed path.1, path.2, ... from the
+// newest to the oldest, keeping at most maxBackups of them, and a new file is
+// created at path. A single write larger than maxBytes is written to a new
+// file without being split.
+//
+// The file is opened with the first write, whose error reports a failure to
+// open it. The writer is safe for concurrent use.
+func NewRotatingFileWriter(path string, maxBytes int64, maxBackups int) io.WriteCloser {
+	return &rotatingFileWriter{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+}
+
+type rotatingFileWriter struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func (w *rotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file. Writing after Close opens it again.
+func (w *rotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *rotatingFileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+func (w *rotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	if w.maxBackups > 0 {
+		if err := os.Remove(w.backupPath(w.maxBackups)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for i := w.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(w.backupPath(i), w.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return w.open()
+}
+
+func (w *rotatingFileWriter) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
@@ -0,0 +1,94 @@
+package logrus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+	w := NewRotatingFileWriter(path, 10, 2)
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	for name, content := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		b, err := ioutil.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(b), name)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "only maxBackups backups must be kept")
+}
+
+func TestRotatingFileWriterAppendsToExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("previous run\n"), 0644))
+
+	w := NewRotatingFileWriter(path, 20, 1)
+	_, err = w.Write([]byte("new run\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	b, err := ioutil.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "previous run\n", string(b))
+	b, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new run\n", string(b))
+}
+
+func TestRotatingFileWriterConcurrentLogging(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+	w := NewRotatingFileWriter(path, 1024, 100)
+	defer w.Close()
+
+	logger := New()
+	logger.Out = w
+	logger.Formatter = &TextFormatter{DisableTimestamp: true, DisableColors: true}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				logger.Info("rotating")
+			}
+		}()
+	}
+	wg.Wait()
+
+	matches, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	total := 0
+	for _, name := range matches {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(1024), name)
+		total += int(info.Size())
+	}
+	assert.Equal(t, 500*len("level=info msg=rotating\n"), total)
+}
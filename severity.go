@@ -0,0 +1,183 @@
+package logrus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is a syslog severity, as defined by RFC 5424, from the most
+// severe SeverityEmergency to SeverityDebug. Every level has a severity, see
+// Level.Severity, and entries may carry a more precise one, see WithSeverity,
+// e.g. to raise alerts without the side effects of the panic and fatal levels.
+type Severity uint8
+
+const (
+	// SeverityEmergency means the system is unusable.
+	SeverityEmergency Severity = iota
+	// SeverityAlert means action must be taken immediately.
+	SeverityAlert
+	// SeverityCritical reports critical conditions.
+	SeverityCritical
+	// SeverityError reports error conditions.
+	SeverityError
+	// SeverityWarning reports warning conditions.
+	SeverityWarning
+	// SeverityNotice reports normal but significant conditions.
+	SeverityNotice
+	// SeverityInfo reports informational messages.
+	SeverityInfo
+	// SeverityDebug reports debug-level messages.
+	SeverityDebug
+)
+
+// SeverityKey is the key of the field set by WithSeverity.
+var SeverityKey = "severity"
+
+var severityNames = []string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+// Convert the Severity to a string. E.g. SeverityAlert becomes "alert".
+func (severity Severity) String() string {
+	if int(severity) < len(severityNames) {
+		return severityNames[severity]
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (severity Severity) MarshalText() ([]byte, error) {
+	if int(severity) < len(severityNames) {
+		return []byte(severityNames[severity]), nil
+	}
+	return nil, fmt.Errorf("not a valid syslog severity %d", severity)
+}
+
+// ParseSeverity takes a syslog severity name, e.g. "alert", and returns the
+// Severity constant. The syslog short names, such as "emerg" or "crit", are
+// also accepted.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "emergency", "emerg":
+		return SeverityEmergency, nil
+	case "alert":
+		return SeverityAlert, nil
+	case "critical", "crit":
+		return SeverityCritical, nil
+	case "error", "err":
+		return SeverityError, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "notice":
+		return SeverityNotice, nil
+	case "info", "informational":
+		return SeverityInfo, nil
+	case "debug":
+		return SeverityDebug, nil
+	}
+	return 0, fmt.Errorf("not a valid syslog severity: %q", s)
+}
+
+// Level returns the level the entries of the severity are logged at. The
+// severities above error are logged at ErrorLevel, so that they never panic
+// nor exit.
+func (severity Severity) Level() Level {
+	switch {
+	case severity <= SeverityError:
+		return ErrorLevel
+	case severity == SeverityWarning:
+		return WarnLevel
+	case severity <= SeverityInfo:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
+
+// Severity returns the syslog severity of the level: PanicLevel is an
+// emergency, FatalLevel is critical, and TraceLevel is debug like DebugLevel.
+func (level Level) Severity() Severity {
+	switch level {
+	case PanicLevel:
+		return SeverityEmergency
+	case FatalLevel:
+		return SeverityCritical
+	case ErrorLevel:
+		return SeverityError
+	case WarnLevel:
+		return SeverityWarning
+	case InfoLevel:
+		return SeverityInfo
+	default:
+		return SeverityDebug
+	}
+}
+
+// WithSeverity adds the syslog severity of the entry as a field, using the
+// key defined in SeverityKey, in place of the severity of its level.
+func (entry *Entry) WithSeverity(severity Severity) *Entry {
+	return entry.WithField(SeverityKey, severity)
+}
+
+// Severity returns the severity set with WithSeverity, or else the severity
+// of the entry level.
+func (entry *Entry) Severity() Severity {
+	if severity, ok := entry.Data[SeverityKey].(Severity); ok {
+		return severity
+	}
+	return entry.Level.Severity()
+}
+
+// LogSeverity logs at the level of the severity, with the severity set on the
+// entry.
+func (entry *Entry) LogSeverity(severity Severity, args ...interface{}) {
+	if entry.Logger.IsLevelEnabled(severity.Level()) || entry.Logger.observesDisabled() {
+		entry.WithSeverity(severity).Log(severity.Level(), args...)
+	}
+}
+
+// Emergency logs at ErrorLevel with the emergency severity.
+func (entry *Entry) Emergency(args ...interface{}) {
+	entry.LogSeverity(SeverityEmergency, args...)
+}
+
+// Alert logs at ErrorLevel with the alert severity.
+func (entry *Entry) Alert(args ...interface{}) {
+	entry.LogSeverity(SeverityAlert, args...)
+}
+
+// Critical logs at ErrorLevel with the critical severity.
+func (entry *Entry) Critical(args ...interface{}) {
+	entry.LogSeverity(SeverityCritical, args...)
+}
+
+// WithSeverity adds the syslog severity of the entry as a field, see
+// Entry.WithSeverity.
+func (logger *Logger) WithSeverity(severity Severity) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithSeverity(severity)
+}
+
+// LogSeverity logs at the level of the severity, with the severity set on the
+// entry.
+func (logger *Logger) LogSeverity(severity Severity, args ...interface{}) {
+	if logger.IsLevelEnabled(severity.Level()) || logger.observesDisabled() {
+		entry := logger.newEntry()
+		entry.LogSeverity(severity, args...)
+		logger.releaseEntry(entry)
+	}
+}
+
+// Emergency logs at ErrorLevel with the emergency severity.
+func (logger *Logger) Emergency(args ...interface{}) {
+	logger.LogSeverity(SeverityEmergency, args...)
+}
+
+// Alert logs at ErrorLevel with the alert severity.
+func (logger *Logger) Alert(args ...interface{}) {
+	logger.LogSeverity(SeverityAlert, args...)
+}
+
+// Critical logs at ErrorLevel with the critical severity.
+func (logger *Logger) Critical(args ...interface{}) {
+	logger.LogSeverity(SeverityCritical, args...)
+}
@@ -0,0 +1,66 @@
+package logrus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for text, severity := range map[string]Severity{
+		"emergency": SeverityEmergency,
+		"emerg":     SeverityEmergency,
+		"Alert":     SeverityAlert,
+		"crit":      SeverityCritical,
+		"err":       SeverityError,
+		"warning":   SeverityWarning,
+		"notice":    SeverityNotice,
+		" info ":    SeverityInfo,
+		"debug":     SeverityDebug,
+	} {
+		got, err := ParseSeverity(text)
+		require.NoError(t, err, text)
+		assert.Equal(t, severity, got, text)
+	}
+
+	_, err := ParseSeverity("loud")
+	assert.EqualError(t, err, `not a valid syslog severity: "loud"`)
+}
+
+func TestLevelSeverity(t *testing.T) {
+	assert.Equal(t, SeverityEmergency, PanicLevel.Severity())
+	assert.Equal(t, SeverityCritical, FatalLevel.Severity())
+	assert.Equal(t, SeverityError, ErrorLevel.Severity())
+	assert.Equal(t, SeverityWarning, WarnLevel.Severity())
+	assert.Equal(t, SeverityInfo, InfoLevel.Severity())
+	assert.Equal(t, SeverityDebug, DebugLevel.Severity())
+	assert.Equal(t, SeverityDebug, TraceLevel.Severity())
+}
+
+func TestSeverityAliasesHaveNoSideEffects(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.Out = &buf
+	logger.Formatter = &TextFormatter{DisableColors: true, DisableTimestamp: true}
+	logger.ExitFunc = func(int) { t.Fatal("an alias must not exit") }
+
+	assert.NotPanics(t, func() {
+		logger.Emergency("datacenter on fire")
+		logger.WithField("disk", "sda").Alert("disk gone")
+		logger.Critical("degraded")
+		logger.LogSeverity(SeverityNotice, "rotated")
+	})
+	assert.Equal(t, "level=error msg=\"datacenter on fire\" severity=emergency\n"+
+		"level=error msg=\"disk gone\" disk=sda severity=alert\n"+
+		"level=error msg=degraded severity=critical\n"+
+		"level=info msg=rotated severity=notice\n", buf.String())
+}
+
+func TestEntrySeverity(t *testing.T) {
+	entry := NewEntry(New())
+	entry.Level = WarnLevel
+	assert.Equal(t, SeverityWarning, entry.Severity())
+	assert.Equal(t, SeverityAlert, entry.WithSeverity(SeverityAlert).Severity())
+}
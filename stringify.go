@@ -0,0 +1,24 @@
+package logrus
+
+import "fmt"
+
+// Stringify wraps a value so that its `%+v` representation is only computed
+// when the entry is actually emitted, both as a field and as an argument of
+// the Print and Printf families:
+//
+//	log.Debugf("state: %s", logrus.Stringify(bigStruct))
+//	log.WithField("state", logrus.Stringify(bigStruct)).Debug("tick")
+//
+// As a field it is a LazyValue, resolved to the string when the entry is
+// logged.
+func Stringify(v interface{}) LazyValue {
+	return func() interface{} {
+		return fmt.Sprintf("%+v", v)
+	}
+}
+
+// String computes the value, so that a LazyValue passed as an argument of the
+// Print and Printf families is only computed when the entry is emitted.
+func (v LazyValue) String() string {
+	return fmt.Sprint(v())
+}
@@ -0,0 +1,57 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingFormatter struct {
+	calls int
+}
+
+func (c *countingFormatter) Format(s fmt.State, verb rune) {
+	c.calls++
+	fmt.Fprintf(s, "calls:%d", c.calls)
+}
+
+func TestStringifyIsLazy(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+
+	state := &countingFormatter{}
+	l.Debugf("state: %s", Stringify(state))
+	l.Debug(Stringify(state))
+	l.WithField("state", Stringify(state)).Debug("tick")
+	assert.Equal(t, 0, state.calls, "Stringify must not format values of disabled levels")
+
+	l.Infof("state: %s", Stringify(state))
+	assert.Equal(t, 1, state.calls)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "state: calls:1", data["msg"])
+}
+
+func TestStringifyField(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New()
+	l.SetOutput(buf)
+	l.SetFormatter(&JSONFormatter{})
+
+	var fired interface{}
+	l.OnEntry = func(entry *Entry) { fired = entry.Data["state"] }
+
+	l.WithField("state", Stringify(struct{ A int }{A: 1})).Info("tick")
+	assert.Equal(t, "{A:1}", fired, "the field must be resolved to its string before the hooks")
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "{A:1}", data["state"])
+}
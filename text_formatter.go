@@ -57,11 +57,13 @@ type TextFormatter struct {
 	// The format to use is the same than for time.Format or time.Parse from the standard
 	// library.
 	// The standard Library already provides a set of predefined format.
+	// TimestampFormatUnix and TimestampFormatUnixMilli print Unix timestamps.
 	TimestampFormat string
 
 	// The fields are sorted by default for a consistent output. For applications
 	// that log extremely frequently and don't use the JSON formatter this may not
-	// be desired.
+	// be desired. When disabled, the fields are written in the order they were
+	// added with WithField{,s}.
 	DisableSorting bool
 
 	// The keys sorting function, when uninitialized it uses sort.Strings.
@@ -77,6 +79,22 @@ type TextFormatter struct {
 	// QuoteEmptyFields will wrap empty fields in quotes if true
 	QuoteEmptyFields bool
 
+	// DisableNewline disables the newline written after each entry, e.g. for
+	// transports framing the messages themselves.
+	DisableNewline bool
+
+	// LevelColors overrides the colors of the levels, as full ANSI escape
+	// sequences, e.g. "\x1b[38;5;208m" for a 256-color orange. The levels not
+	// in the map keep their default color. It has no effect when colors are
+	// disabled.
+	LevelColors map[Level]string
+
+	// ValueFormatter, when set, renders the values of the fields in place of
+	// `fmt.Sprint`, e.g. to JSON-encode nested structures. The rendered value
+	// is still quoted when needed. It is not called for the time, level,
+	// message, error and caller keys.
+	ValueFormatter func(key string, value interface{}) string
+
 	// Whether the logger's out is to a terminal
 	isTerminal bool
 
@@ -133,12 +151,20 @@ func (f *TextFormatter) isColored() bool {
 func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 	data := make(Fields)
 	for k, v := range entry.Data {
+		if lazy, ok := v.(LazyValue); ok {
+			v = lazy()
+		}
 		data[k] = v
 	}
 	prefixFieldClashes(data, f.FieldMap, entry.HasCaller())
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
+	var keys []string
+	if f.DisableSorting {
+		keys = entry.fieldKeys(data)
+	} else {
+		keys = make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
 	}
 
 	var funcVal, fileVal string
@@ -207,7 +233,7 @@ func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 			var value interface{}
 			switch {
 			case key == f.FieldMap.resolve(FieldKeyTime):
-				value = entry.Time.Format(timestampFormat)
+				value = formatTimestamp(entry.Time, timestampFormat)
 			case key == f.FieldMap.resolve(FieldKeyLevel):
 				value = entry.Level.String()
 			case key == f.FieldMap.resolve(FieldKeyMsg):
@@ -219,19 +245,26 @@ func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 			case key == f.FieldMap.resolve(FieldKeyFile) && entry.HasCaller():
 				value = fileVal
 			default:
-				value = data[key]
+				value = f.fieldValue(key, data[key])
 			}
 			f.appendKeyValue(b, key, value)
 		}
 	}
 
-	b.WriteByte('\n')
+	if !f.DisableNewline {
+		b.WriteByte('\n')
+	}
 	return b.Bytes(), nil
 }
 
-func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []string, data Fields, timestampFormat string) {
+// levelColor returns the ANSI escape sequence of the level color.
+func (f *TextFormatter) levelColor(level Level) string {
+	if color, ok := f.LevelColors[level]; ok {
+		return color
+	}
+
 	var levelColor int
-	switch entry.Level {
+	switch level {
 	case DebugLevel, TraceLevel:
 		levelColor = gray
 	case WarnLevel:
@@ -243,6 +276,11 @@ func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []strin
 	default:
 		levelColor = blue
 	}
+	return "\x1b[" + strconv.Itoa(levelColor) + "m"
+}
+
+func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []string, data Fields, timestampFormat string) {
+	levelColor := f.levelColor(entry.Level)
 
 	levelText := strings.ToUpper(entry.Level.String())
 	if !f.DisableLevelTruncation && !f.PadLevelText {
@@ -282,15 +320,15 @@ func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []strin
 
 	switch {
 	case f.DisableTimestamp:
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m%s %-44s ", levelColor, levelText, caller, entry.Message)
+		fmt.Fprintf(b, "%s%s\x1b[0m%s %-44s ", levelColor, levelText, caller, entry.Message)
 	case !f.FullTimestamp:
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%04d]%s %-44s ", levelColor, levelText, int(entry.Time.Sub(baseTimestamp)/time.Second), caller, entry.Message)
+		fmt.Fprintf(b, "%s%s\x1b[0m[%04d]%s %-44s ", levelColor, levelText, int(entry.Time.Sub(baseTimestamp)/time.Second), caller, entry.Message)
 	default:
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%s]%s %-44s ", levelColor, levelText, entry.Time.Format(timestampFormat), caller, entry.Message)
+		fmt.Fprintf(b, "%s%s\x1b[0m[%v]%s %-44s ", levelColor, levelText, formatTimestamp(entry.Time, timestampFormat), caller, entry.Message)
 	}
 	for _, k := range keys {
-		v := data[k]
-		fmt.Fprintf(b, " \x1b[%dm%s\x1b[0m=", levelColor, k)
+		v := f.fieldValue(k, data[k])
+		fmt.Fprintf(b, " %s%s\x1b[0m=", levelColor, k)
 		f.appendValue(b, v)
 	}
 }
@@ -325,6 +363,15 @@ func (f *TextFormatter) appendKeyValue(b *bytes.Buffer, key string, value interf
 	f.appendValue(b, value)
 }
 
+// fieldValue returns the value of a field, rendered by the ValueFormatter if
+// any.
+func (f *TextFormatter) fieldValue(key string, value interface{}) interface{} {
+	if f.ValueFormatter == nil {
+		return value
+	}
+	return f.ValueFormatter(key, value)
+}
+
 func (f *TextFormatter) appendValue(b *bytes.Buffer, value interface{}) {
 	stringVal, ok := value.(string)
 	if !ok {
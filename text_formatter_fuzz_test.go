@@ -0,0 +1,54 @@
+//go:build go1.18
+// +build go1.18
+
+package logrus_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/sirupsen/logrus"
+	. "github.com/sirupsen/logrus/internal/testutils"
+)
+
+func FuzzTextFormatterQuoting(f *testing.F) {
+	for _, seed := range []string{
+		"", "abcd", "a b", "k=a b=c", `"quoted"`, `a\"b`, "tab\there",
+		"new\nline", "\x00\x1b[31m", "héllo", "\xff\xfe", "end\\",
+	} {
+		f.Add(seed)
+	}
+
+	formatters := []*TextFormatter{
+		{DisableColors: true, DisableTimestamp: true},
+		{DisableColors: true, DisableTimestamp: true, QuoteEmptyFields: true},
+		{DisableColors: true, DisableTimestamp: true, ForceQuote: true},
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		entry := WithField("k", value)
+		entry.Message = "m"
+
+		for _, formatter := range formatters {
+			b, err := formatter.Format(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			line := string(b)
+			if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+				t.Fatalf("expected a single line, got %q", line)
+			}
+
+			fields, err := ParseText(strings.TrimSuffix(line, "\n"))
+			if err != nil {
+				t.Fatalf("unable to parse %q: %v", line, err)
+			}
+			if fields["k"] != value {
+				t.Fatalf("value %q was parsed back as %q from %q", value, fields["k"], line)
+			}
+			if fields["msg"] != "m" || fields["level"] != "panic" {
+				t.Fatalf("unexpected fields %v parsed from %q", fields, line)
+			}
+		}
+	})
+}
@@ -2,6 +2,7 @@ package logrus
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -64,6 +65,11 @@ func TestQuoting(t *testing.T) {
 	checkQuoting(true, "&foobar")
 	checkQuoting(true, "x y")
 	checkQuoting(true, "x,y")
+	checkQuoting(true, "k=a b=c")
+	checkQuoting(true, "a=b")
+	checkQuoting(true, `say "hi"`)
+	checkQuoting(true, "tab\there")
+	checkQuoting(true, "nul\x00")
 	checkQuoting(false, errors.New("invalid"))
 	checkQuoting(true, errors.New("invalid argument"))
 
@@ -296,6 +302,26 @@ func TestDisableTimestampWithColoredOutput(t *testing.T) {
 	}
 }
 
+func TestCustomLevelColors(t *testing.T) {
+	const orange = "\x1b[38;5;208m"
+	entry := WithField("test", "test")
+	entry.Level = WarnLevel
+
+	tf := &TextFormatter{ForceColors: true, LevelColors: map[Level]string{WarnLevel: orange}}
+	b, _ := tf.Format(entry)
+	assert.True(t, strings.HasPrefix(string(b), orange+"WARN\x1b[0m"), "custom color expected, got %q", b)
+	assert.Contains(t, string(b), orange+"test\x1b[0m=")
+
+	entry.Level = ErrorLevel
+	b, _ = tf.Format(entry)
+	assert.True(t, strings.HasPrefix(string(b), "\x1b[31mERRO\x1b[0m"), "default color expected, got %q", b)
+
+	entry.Level = WarnLevel
+	tf = &TextFormatter{ForceColors: true, DisableColors: true, LevelColors: map[Level]string{WarnLevel: orange}}
+	b, _ = tf.Format(entry)
+	assert.NotContains(t, string(b), "\x1b[", "colors must be disabled")
+}
+
 func TestNewlineBehavior(t *testing.T) {
 	tf := &TextFormatter{ForceColors: true}
 
@@ -598,3 +624,89 @@ func TestCustomSorting(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, strings.HasPrefix(string(b), "prefix="), "format output is %q", string(b))
 }
+
+func TestDisableSortingKeepsInsertionOrder(t *testing.T) {
+	formatter := &TextFormatter{DisableColors: true, DisableTimestamp: true, DisableSorting: true}
+
+	entry := NewEntry(New()).
+		WithField("zone", "eu").
+		WithFields(Fields{"request": 42}).
+		WithField("alpha", true).
+		WithTime(time.Now()).
+		WithField("zone", "us")
+	entry.Data["direct"] = 1
+	entry.Message = "ordered"
+	entry.Level = InfoLevel
+
+	b, err := formatter.Format(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "level=info msg=ordered zone=us request=42 alpha=true direct=1\n", string(b))
+
+	child := entry.WithField("beta", 2).Dup()
+	child.Level = InfoLevel
+	b, err = formatter.Format(child)
+	require.NoError(t, err)
+	assert.Equal(t, "level=info zone=us request=42 alpha=true beta=2 direct=1\n", string(b))
+}
+
+func TestTextUnixTimestampFormats(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 30, 45, 123456789, time.UTC)
+	entry := &Entry{Time: now, Message: "epoch"}
+
+	tf := &TextFormatter{DisableColors: true, TimestampFormat: TimestampFormatUnix}
+	b, err := tf.Format(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "time=1622550645 level=panic msg=epoch\n", string(b))
+
+	tf = &TextFormatter{DisableColors: true, TimestampFormat: TimestampFormatUnixMilli}
+	b, err = tf.Format(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "time=1622550645123 level=panic msg=epoch\n", string(b))
+
+	tf = &TextFormatter{ForceColors: true, FullTimestamp: true, TimestampFormat: TimestampFormatUnixMilli}
+	b, err = tf.Format(entry)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "[1622550645123]")
+}
+
+func TestTextValueFormatter(t *testing.T) {
+	tf := &TextFormatter{
+		DisableColors:    true,
+		DisableTimestamp: true,
+		ValueFormatter: func(key string, value interface{}) string {
+			if m, ok := value.(map[string]int); ok {
+				b, _ := json.Marshal(m)
+				return string(b)
+			}
+			return fmt.Sprint(value)
+		},
+	}
+
+	entry := WithFields(Fields{"counts": map[string]int{"a": 1, "b": 2}, "user": "alice", "n": 3})
+	entry.Message = "done"
+	entry.Level = InfoLevel
+	b, err := tf.Format(entry)
+	require.NoError(t, err)
+	assert.Equal(t, `level=info msg=done counts="{\"a\":1,\"b\":2}" n=3 user=alice`+"\n", string(b))
+
+	tf.ForceColors, tf.DisableColors = true, false
+	b, err = tf.Format(entry)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "counts\x1b[0m="+`"{\"a\":1,\"b\":2}"`)
+}
+
+func TestTextDisableNewline(t *testing.T) {
+	for _, tf := range []*TextFormatter{{DisableColors: true}, {ForceColors: true}} {
+		entry := WithField("k", "v")
+		entry.Message = "framed"
+
+		b, err := tf.Format(entry)
+		require.NoError(t, err)
+		assert.Equal(t, byte('\n'), b[len(b)-1], "expected a trailing newline by default")
+
+		tf.DisableNewline = true
+		b, err = tf.Format(entry)
+		require.NoError(t, err)
+		assert.NotEqual(t, byte('\n'), b[len(b)-1], "expected no trailing newline, got %q", b)
+	}
+}
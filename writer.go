@@ -3,6 +3,7 @@ package logrus
 import (
 	"bufio"
 	"io"
+	"log"
 	"runtime"
 )
 
@@ -20,6 +21,15 @@ func (logger *Logger) WriterLevel(level Level) *io.PipeWriter {
 	return NewEntry(logger).WriterLevel(level)
 }
 
+// StdLogger returns a standard library logger writing each line to the logger
+// at the given log level, for code taking a *log.Logger. Its prefix and flags
+// are empty, the time and other fields being added by the formatter. The
+// underlying WriterLevel pipe is closed once the returned logger is garbage
+// collected.
+func (logger *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(logger.WriterLevel(level), "", 0)
+}
+
 func (entry *Entry) Writer() *io.PipeWriter {
 	return entry.WriterLevel(InfoLevel)
 }
@@ -56,6 +66,7 @@ func (entry *Entry) WriterLevel(level Level) *io.PipeWriter {
 
 func (entry *Entry) writerScanner(reader *io.PipeReader, printFunc func(args ...interface{})) {
 	scanner := bufio.NewScanner(reader)
+	scanner.Split(scanLinesOrChunks)
 	for scanner.Scan() {
 		printFunc(scanner.Text())
 	}
@@ -65,6 +76,17 @@ func (entry *Entry) writerScanner(reader *io.PipeReader, printFunc func(args ...
 	reader.Close()
 }
 
+// scanLinesOrChunks splits lines like bufio.ScanLines, but cuts the lines too
+// long for the scanner buffer into chunks instead of failing with
+// bufio.ErrTooLong, which would stop the logging of the writer.
+func scanLinesOrChunks(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	advance, token, err = bufio.ScanLines(data, atEOF)
+	if advance == 0 && token == nil && err == nil && len(data) >= bufio.MaxScanTokenSize {
+		return bufio.MaxScanTokenSize, data[:bufio.MaxScanTokenSize], nil
+	}
+	return advance, token, err
+}
+
 func writerFinalizer(writer *io.PipeWriter) {
 	writer.Close()
 }
@@ -1,10 +1,18 @@
 package logrus_test
 
 import (
+	"bufio"
+	"io"
 	"log"
 	"net/http"
+	"strings"
+	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func ExampleLogger_Writer_httpServer() {
@@ -32,3 +40,41 @@ func ExampleLogger_Writer_stdlib() {
 	// Not logrus imported under the name `log`.
 	log.SetOutput(logger.Writer())
 }
+
+func TestWriterLevelLogsEachLine(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	long := strings.Repeat("x", bufio.MaxScanTokenSize+10)
+	w := logger.WriterLevel(logrus.WarnLevel)
+	_, err := io.WriteString(w, "first line\nsecond line\n"+long+"\nlast line")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Eventually(t, func() bool { return len(hook.AllEntries()) == 5 }, time.Second, time.Millisecond)
+
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		assert.Equal(t, logrus.WarnLevel, entry.Level)
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"first line", "second line", long[:bufio.MaxScanTokenSize], "xxxxxxxxxx", "last line"}, messages)
+}
+
+func TestLoggerStdLogger(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	legacy := func(l *log.Logger) {
+		l.Printf("connecting to %s", "db")
+		l.Println("connected")
+	}
+	legacy(logger.StdLogger(logrus.WarnLevel))
+
+	assert.Eventually(t, func() bool { return len(hook.AllEntries()) == 2 }, time.Second, time.Millisecond)
+
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		assert.Equal(t, logrus.WarnLevel, entry.Level)
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"connecting to db", "connected"}, messages)
+}